@@ -35,6 +35,7 @@ var finished []string = []string{
 }
 
 type config struct {
+	options        *request.Options
 	logFolder      string
 	separator      string
 	requestTimeout int
@@ -78,7 +79,7 @@ func main() {
 			defer cancel()
 
 			start := time.Now()
-			req, err := request.NewRequest(requestString)
+			req, err := request.NewRequest(requestString, conf.options)
 			if err != nil {
 				emoji.Printf("error: %s :sad_but_relieved_face:\n", err)
 				l.Error.Sugar().Errorf("error: %s", err)
@@ -175,7 +176,39 @@ func initConfig() (*config, error) {
 		logFolder = "./logs"
 	}
 
+	// Zero is a valid precision, so floats are printed as they are only if the option is not set.
+	var floatPrecision *int
+	if viper.IsSet("float_precision") {
+		digits := viper.GetInt("float_precision")
+		floatPrecision = &digits
+	}
+
+	timezone := viper.GetString("timezone")
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := request.ParseSchema(viper.GetStringSlice("schema"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &config{
+		options: &request.Options{
+			NullMarkers:      viper.GetStringSlice("null_markers"),
+			DateLayouts:      viper.GetStringSlice("date_layouts"),
+			TimestampLayouts: viper.GetStringSlice("timestamp_layouts"),
+			Location:         location,
+			TrueValues:       viper.GetStringSlice("true_values"),
+			FalseValues:      viper.GetStringSlice("false_values"),
+			Precision:        floatPrecision,
+			Schema:           schema,
+			StrictTypes:      viper.GetBool("strict_types"),
+		},
 		logFolder:      logFolder,
 		separator:      separator,
 		requestTimeout: requestTimeout,
@@ -0,0 +1,210 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	aggCount string = "COUNT"
+	aggSum   string = "SUM"
+	aggAvg   string = "AVG"
+	aggMin   string = "MIN"
+	aggMax   string = "MAX"
+)
+
+var aggregateFunctions = []string{aggCount, aggSum, aggAvg, aggMin, aggMax}
+
+// Aggregate is a call of the aggregate function in SELECT statement.
+// Field is "*" for COUNT(*), which counts all rows of the group.
+// Distinct is set for COUNT(DISTINCT field), which counts unique values.
+type Aggregate struct {
+	Function string
+	Field    string
+	Distinct bool
+	formats  *formats
+}
+
+// String returns the name of the aggregate as it is printed in the results.
+func (a *Aggregate) String() string {
+	if a.Distinct {
+		return fmt.Sprintf("%s(DISTINCT %s)", a.Function, a.Field)
+	}
+	return fmt.Sprintf("%s(%s)", a.Function, a.Field)
+}
+
+// Eval returns the computed value of the aggregate from the row of the groups.
+func (a *Aggregate) Eval(row Record) Variable {
+	return a.formats.parse(row.Get(a.String()))
+}
+
+// GetFields returns the name of the aggregate, since its value
+// is taken from the row of the groups by this name.
+func (a *Aggregate) GetFields() []string {
+	return []string{a.String()}
+}
+
+// aggregateState accumulates values of a single aggregate in a group.
+type aggregateState struct {
+	distinct *distinctCounter
+	min      Variable
+	max      Variable
+	sumFloat float64
+	sumInt   int
+	count    int
+	numbers  int
+	isFloat  bool
+}
+
+// add accumulates the value of the field, see Request.fieldValue.
+// Any value is counted by COUNT(*).
+func (s *aggregateState) add(a *Aggregate, value Variable) {
+	if a.Field == allFields {
+		s.count++
+		return
+	}
+
+	if isNull(value) {
+		return
+	}
+	if a.Distinct {
+		if s.distinct == nil {
+			s.distinct = newDistinctCounter()
+		}
+		s.distinct.add(value.String())
+		return
+	}
+	s.count++
+	s.addExtremes(value)
+	s.addNumber(value)
+}
+
+// addExtremes keeps the value if it is the minimum or the maximum of the group.
+func (s *aggregateState) addExtremes(value Variable) {
+	if s.count == 1 || compareValues(value, s.min) < 0 {
+		s.min = value
+	}
+	if s.count == 1 || compareValues(value, s.max) > 0 {
+		s.max = value
+	}
+}
+
+// addNumber adds the value to the sum if it is the number.
+func (s *aggregateState) addNumber(value Variable) {
+	switch value.defineType() {
+	case typeInteger:
+		s.sumInt += value.toInteger()
+	case typeFloat:
+		s.sumFloat += value.toFloat()
+		s.isFloat = true
+	default:
+		return
+	}
+	s.numbers++
+}
+
+// result returns the value of the aggregate. Empty string is returned if
+// the group has no values to compute the aggregate, e.g. SUM of strings.
+func (s *aggregateState) result(a *Aggregate) string {
+	switch a.Function {
+	case aggCount:
+		if a.Distinct {
+			if s.distinct == nil {
+				return "0"
+			}
+			return strconv.Itoa(s.distinct.count())
+		}
+		return strconv.Itoa(s.count)
+	case aggMin, aggMax:
+		if s.count == 0 {
+			return ""
+		}
+		if a.Function == aggMin {
+			return s.min.String()
+		}
+		return s.max.String()
+	}
+
+	if s.numbers == 0 {
+		return ""
+	}
+	if a.Function == aggAvg {
+		return formatFloat((s.sumFloat + float64(s.sumInt)) / float64(s.numbers))
+	}
+	if s.isFloat {
+		return formatFloat(s.sumFloat + float64(s.sumInt))
+	}
+	return strconv.Itoa(s.sumInt)
+}
+
+// group keeps values of GROUP BY expressions and aggregates states of a single group.
+type group struct {
+	values RowData
+	states []*aggregateState
+}
+
+// aggregator groups rows by GROUP BY expressions and accumulates aggregates
+// for every group. Groups are kept in the order of their first appearance.
+type aggregator struct {
+	request *Request
+	groups  map[string]*group
+	keys    []string
+}
+
+func newAggregator(r *Request) *aggregator {
+	return &aggregator{request: r, groups: make(map[string]*group)}
+}
+
+func (a *aggregator) add(data RowData) {
+	values := make([]string, len(a.request.GroupBy))
+	for ind, expr := range a.request.GroupBy {
+		values[ind] = evalData(expr, data, a.request.formats).String()
+	}
+	key := strings.Join(values, "\x00")
+
+	g, ok := a.groups[key]
+	if !ok {
+		g = a.newGroup()
+		for ind, expr := range a.request.GroupBy {
+			g.values[expr.String()] = values[ind]
+		}
+		a.groups[key] = g
+		a.keys = append(a.keys, key)
+	}
+
+	for ind, agg := range a.request.Aggregates {
+		g.states[ind].add(agg, a.request.fieldValue(agg.Field, data))
+	}
+}
+
+func (a *aggregator) newGroup() *group {
+	g := &group{values: make(RowData), states: make([]*aggregateState, len(a.request.Aggregates))}
+	for ind := range g.states {
+		g.states[ind] = &aggregateState{}
+	}
+	return g
+}
+
+// rows returns a row for every group with GROUP BY values and results of aggregates.
+func (a *aggregator) rows() []RowData {
+	// Aggregates without GROUP BY always return a single row, even for empty input.
+	if len(a.keys) == 0 && len(a.request.GroupBy) == 0 {
+		a.groups[""] = a.newGroup()
+		a.keys = append(a.keys, "")
+	}
+
+	data := make([]RowData, 0, len(a.keys))
+	for _, key := range a.keys {
+		g := a.groups[key]
+		row := make(RowData)
+		for field, value := range g.values {
+			row[field] = value
+		}
+		for ind, agg := range a.request.Aggregates {
+			row[agg.String()] = g.states[ind].result(agg)
+		}
+		data = append(data, row)
+	}
+	return data
+}
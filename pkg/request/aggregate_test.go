@@ -0,0 +1,190 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateStateResult(t *testing.T) {
+	values := []string{"4", "", "10", "2.5", "abc"}
+	tests := []struct {
+		agg    *Aggregate
+		name   string
+		expect string
+	}{
+		{name: "countAll", agg: &Aggregate{Function: aggCount, Field: "*"}, expect: "5"},
+		{name: "count", agg: &Aggregate{Function: aggCount, Field: "value"}, expect: "4"},
+		{name: "sum", agg: &Aggregate{Function: aggSum, Field: "value"}, expect: "16.5"},
+		{name: "avg", agg: &Aggregate{Function: aggAvg, Field: "value"}, expect: "5.5"},
+		{name: "min", agg: &Aggregate{Function: aggMin, Field: "value"}, expect: "2.5"},
+		{name: "max", agg: &Aggregate{Function: aggMax, Field: "value"}, expect: "abc"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			state := &aggregateState{}
+			for _, value := range values {
+				state.add(tc.agg, Data(value))
+			}
+			assert.Equal(t, tc.expect, state.result(tc.agg))
+		})
+	}
+}
+
+func TestAggregateStateIntegerSum(t *testing.T) {
+	agg := &Aggregate{Function: aggSum, Field: "value"}
+	state := &aggregateState{}
+	for _, value := range []string{"4", "10"} {
+		state.add(agg, Data(value))
+	}
+	assert.Equal(t, "14", state.result(agg))
+
+	empty := &aggregateState{}
+	empty.add(agg, Data(""))
+	assert.Equal(t, "", empty.result(agg))
+}
+
+func TestRequestDoGroupBy(t *testing.T) {
+	req, err := NewRequest(`SELECT location, COUNT(*), SUM(new_cases), AVG(new_cases), MIN(date), MAX(new_cases)
+	FROM ./test/owid-covid-data.csv
+	GROUP BY location
+	ORDER BY location DESC;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{
+		{
+			"location":       "Ukraine",
+			"COUNT(*)":       "11",
+			"SUM(new_cases)": "4957.0",
+			"AVG(new_cases)": "450.6363636363636",
+			"MIN(date)":      "2020-04-20",
+			"MAX(new_cases)": "578.0",
+		},
+		{
+			"location":       "Russia",
+			"COUNT(*)":       "11",
+			"SUM(new_cases)": "63645.0",
+			"AVG(new_cases)": "5785.909090909091",
+			"MIN(date)":      "2020-04-20",
+			"MAX(new_cases)": "7099.0",
+		},
+	}, result.Data)
+	assert.Equal(t, 17, result.MaxLength["AVG(new_cases)"])
+}
+
+func TestRequestDoAggregateWithoutGroups(t *testing.T) {
+	req, err := NewRequest(
+		"SELECT COUNT(*), SUM(icu_patients) FROM ./test/owid-covid-data.csv WHERE location = Belarus;", nil,
+	)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{{"COUNT(*)": "0", "SUM(icu_patients)": ""}}, result.Data)
+}
+
+func TestAggregationErrors(t *testing.T) {
+	tests := []TestError{
+		{
+			name:      "notGrouped",
+			reqString: "SELECT location, date, SUM(new_cases) FROM ./test/owid-covid-data.csv GROUP BY location;",
+			err:       "selected option: date should be used in GROUP BY or in aggregate function",
+		},
+		{
+			name:      "orderNotGrouped",
+			reqString: "SELECT location, SUM(new_cases) FROM ./test/owid-covid-data.csv GROUP BY location ORDER BY date;",
+			err:       "order option: date should be used in GROUP BY",
+		},
+		{
+			name:      "notGroupedExpression",
+			reqString: "SELECT location, COUNT(*) FROM ./test/owid-covid-data.csv GROUP BY UPPER(location);",
+			err:       "selected option: location should be used in GROUP BY or in aggregate function",
+		},
+		{
+			name:      "havingNotGrouped",
+			reqString: "SELECT location FROM ./test/owid-covid-data.csv GROUP BY location HAVING date > 2020-04-20;",
+			err:       "having option: date should be used in GROUP BY or in aggregate function",
+		},
+		{
+			name:      "havingWithoutGroups",
+			reqString: "SELECT location FROM ./test/owid-covid-data.csv HAVING location = russia;",
+			err:       "HAVING can be used only with GROUP BY or aggregate functions",
+		},
+		{
+			name:      "aggregateInWhere",
+			reqString: "SELECT location FROM ./test/owid-covid-data.csv WHERE SUM(new_cases) > 5;",
+			err:       "aggregate function SUM is not allowed in WHERE statement",
+		},
+		{
+			name:      "unknownField",
+			reqString: "SELECT SUM(cases) FROM ./test/owid-covid-data.csv;",
+			err:       "cannot find SUM option: cases in headers",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewRequest(tc.reqString, nil)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
+
+func TestRequestDoHaving(t *testing.T) {
+	req, err := NewRequest(`SELECT location, COUNT(*)
+	FROM ./test/owid-covid-data.csv
+	WHERE date >= 2020-04-25
+	GROUP BY location
+	HAVING SUM(new_cases) > 10000 AND MAX(new_cases) >= 7000 OR location = ukraine;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+	assert.Equal(t, []*Aggregate{
+		{Function: aggCount, Field: "*"},
+		{Function: aggSum, Field: "new_cases"},
+		{Function: aggMax, Field: "new_cases"},
+	}, req.Aggregates)
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{
+		{"location": "Russia", "COUNT(*)": "6", "SUM(new_cases)": "37876.0", "MAX(new_cases)": "7099.0"},
+		{"location": "Ukraine", "COUNT(*)": "6", "SUM(new_cases)": "2759.0", "MAX(new_cases)": "540.0"},
+	}, result.Data)
+
+	req, err = NewRequest(`SELECT location FROM ./test/owid-covid-data.csv
+	GROUP BY location HAVING COUNT(*) < 5;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+	result, err = req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Empty(t, result.Data)
+}
+
+func TestRequestDoGroupByExpression(t *testing.T) {
+	req, err := NewRequest(`SELECT UPPER(location), SUM(new_cases) AS total
+	FROM ./test/owid-covid-data.csv
+	GROUP BY UPPER(location)
+	ORDER BY MAX(new_cases);`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	var rows []string
+	for _, row := range result.Data {
+		rows = append(rows, row["UPPER(location)"]+" "+row["total"])
+	}
+	assert.Equal(t, []string{"UKRAINE 4957.0", "RUSSIA 63645.0"}, rows)
+}
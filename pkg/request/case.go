@@ -0,0 +1,64 @@
+package request
+
+import "strings"
+
+// When is a single branch of the CASE expression.
+type When struct {
+	Condition Condition
+	Result    Expression
+}
+
+// Case returns the result of the first branch which condition is True.
+// If no condition is True, it returns the Else value or NULL if Else is nil.
+type Case struct {
+	Whens []*When
+	Else  Expression
+}
+
+// Eval computes the value of the CASE expression for the row.
+func (c *Case) Eval(row Record) Variable {
+	for _, when := range c.Whens {
+		if when.Condition.Check(row) == True {
+			return when.Result.Eval(row)
+		}
+	}
+	if c.Else == nil {
+		return Data("")
+	}
+	return c.Else.Eval(row)
+}
+
+// GetFields returns unique fields used in the conditions and in the results.
+func (c *Case) GetFields() []string {
+	var fields []string
+	addFields := func(fieldsToAdd []string) {
+		for _, field := range fieldsToAdd {
+			if !sliceHasString(field, fields) {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	for _, when := range c.Whens {
+		addFields(when.Condition.GetFields())
+		addFields(when.Result.GetFields())
+	}
+	if c.Else != nil {
+		addFields(c.Else.GetFields())
+	}
+	return fields
+}
+
+// String returns the CASE expression as it is written in the request.
+func (c *Case) String() string {
+	var b strings.Builder
+	b.WriteString(kwCase)
+	for _, when := range c.Whens {
+		b.WriteString(" " + kwWhen + " " + when.Condition.String() + " " + kwThen + " " + when.Result.String())
+	}
+	if c.Else != nil {
+		b.WriteString(" " + kwElse + " " + c.Else.String())
+	}
+	b.WriteString(" " + kwEnd)
+	return b.String()
+}
@@ -0,0 +1,134 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseEval(t *testing.T) {
+	level := &Case{
+		Whens: []*When{
+			{
+				Condition: &Criterion{Left: &Column{Name: "value"}, Symbol: greater, Right: &Literal{Value: Data("5000")}},
+				Result:    &Literal{Value: Data("high")},
+			},
+			{
+				Condition: &Criterion{Left: &Column{Name: "value"}, Symbol: greater, Right: &Literal{Value: Data("450")}},
+				Result:    &Literal{Value: Data("medium")},
+			},
+		},
+		Else: &Literal{Value: Data("low")},
+	}
+	tests := []struct {
+		expr   Expression
+		name   string
+		value  string
+		result string
+	}{
+		{name: "firstBranch", expr: level, value: "5966.0", result: "high"},
+		{name: "secondBranch", expr: level, value: "467.0", result: "medium"},
+		{name: "else", expr: level, value: "261.0", result: "low"},
+		{name: "unknownCondition", expr: level, value: "", result: "low"},
+		{name: "noElse", expr: &Case{Whens: level.Whens}, value: "261.0", result: ""},
+		{
+			name:   "coalesce",
+			expr:   &Function{Name: "COALESCE", Args: []Expression{&Column{Name: "value"}, &Literal{Value: Data("0")}}},
+			value:  "",
+			result: "0",
+		},
+		{
+			name:   "coalesceNotNull",
+			expr:   &Function{Name: "COALESCE", Args: []Expression{&Column{Name: "value"}, &Literal{Value: Data("0")}}},
+			value:  "7315.0",
+			result: "7315.0",
+		},
+		{
+			name:   "nullif",
+			expr:   &Function{Name: "NULLIF", Args: []Expression{&Column{Name: "value"}, &Literal{Value: Data("0")}}},
+			value:  "0.0",
+			result: "",
+		},
+		{
+			name:   "nullifDifferent",
+			expr:   &Function{Name: "NULLIF", Args: []Expression{&Column{Name: "value"}, &Literal{Value: Data("0")}}},
+			value:  "540.0",
+			result: "540.0",
+		},
+	}
+
+	index := IndexMap{"value": 0}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, Data(tc.result), tc.expr.Eval(Row{Index: index, Line: []string{tc.value}}))
+		})
+	}
+}
+
+func TestCaseString(t *testing.T) {
+	level := &Case{
+		Whens: []*When{{
+			Condition: &Logical{
+				Left: &Logical{
+					Left:     &Criterion{Left: &Column{Name: "new_cases"}, Symbol: greater, Right: &Literal{Value: Data("5000")}},
+					Right:    &IsNull{Expr: &Column{Name: "new_tests"}},
+					Operator: or,
+				},
+				Right:    &Negation{Condition: newIn(&Column{Name: "location"}, false)},
+				Operator: and,
+			},
+			Result: &Literal{Value: Data("high")},
+		}},
+		Else: &Column{Name: "location"},
+	}
+	assert.Equal(
+		t,
+		"CASE WHEN (new_cases > 5000 OR new_tests IS NULL) AND NOT location IN () THEN 'high' ELSE location END",
+		level.String(),
+	)
+	assert.Equal(t, []string{"new_cases", "new_tests", "location"}, level.GetFields())
+}
+
+func TestRequestDoCase(t *testing.T) {
+	req, err := NewRequest(`SELECT date,
+	CASE WHEN new_cases > 5000 THEN 'high' WHEN new_cases > 450 THEN 'medium' ELSE 'low' END AS level,
+	COALESCE(new_tests, 0) AS tests
+	FROM ./test/owid-covid-data.csv
+	WHERE location = Ukraine AND COALESCE(new_tests, 0) = 0
+	ORDER BY new_cases / 2 DESC;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	var rows []string
+	for _, row := range result.Data {
+		rows = append(rows, row["date"]+" "+row["level"]+" "+row["tests"])
+	}
+	assert.Equal(t, []string{
+		"2020-04-23 medium 0",
+		"2020-04-26 medium 0",
+		"2020-04-25 medium 0",
+		"2020-04-24 medium 0",
+		"2020-04-22 medium 0",
+		"2020-04-21 low 0",
+		"2020-04-20 low 0",
+	}, rows)
+
+	req, err = NewRequest(`SELECT CASE WHEN new_cases > 5000 THEN 'high' ELSE 'low' END AS level, COUNT(*) AS days
+	FROM ./test/owid-covid-data.csv
+	GROUP BY level
+	ORDER BY days DESC;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err = req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{
+		{"level": "low", "days": "13", "CASE WHEN new_cases > 5000 THEN 'high' ELSE 'low' END": "low", "COUNT(*)": "13"},
+		{"level": "high", "days": "9", "CASE WHEN new_cases > 5000 THEN 'high' ELSE 'low' END": "high", "COUNT(*)": "9"},
+	}, result.Data)
+}
@@ -0,0 +1,180 @@
+package request
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// typeNames maps the names of the types in requests and schemas to the types of the values.
+var typeNames = map[string]string{
+	"INT":       typeInteger,
+	"INTEGER":   typeInteger,
+	"FLOAT":     typeFloat,
+	"DOUBLE":    typeFloat,
+	"STRING":    typeString,
+	"TEXT":      typeString,
+	"VARCHAR":   typeString,
+	"DATE":      typeDate,
+	"TIMESTAMP": typeTimestamp,
+	"BOOL":      typeBool,
+	"BOOLEAN":   typeBool,
+}
+
+// typedData is the value of the declared type or of the type defined with the formats
+// of the request, see formats.parse. Unlike Data, its type does not depend on the content
+// alone, so the STRING 007 is not the number. The content is always valid for the type, see castValue.
+type typedData struct {
+	Data
+	dataType string
+	// date is the parsed date or timestamp, it keeps the time zone of the value.
+	date *Date
+	// spelling is set if the value is one of the spellings of the booleans,
+	// which can be the number too, and truth is its boolean.
+	spelling bool
+	truth    bool
+}
+
+func (d typedData) defineType() string {
+	return d.dataType
+}
+
+func (d typedData) isInteger() bool {
+	return d.dataType == typeInteger
+}
+
+func (d typedData) isFloat() bool {
+	return isNumber(d.dataType)
+}
+
+func (d typedData) isDate() bool {
+	return d.dataType == typeDate
+}
+
+func (d typedData) isTimestamp() bool {
+	return d.dataType == typeTimestamp
+}
+
+func (d typedData) toDate() *Date {
+	if d.date == nil {
+		return d.Data.toDate()
+	}
+	return d.date
+}
+
+func (d typedData) isBool() bool {
+	return d.dataType == typeBool || d.spelling
+}
+
+func (d typedData) toBool() bool {
+	return d.truth
+}
+
+// dateValue returns the value of the date or of the timestamp. The value is Data
+// in UTC, since Data is read in UTC, and it keeps its time zone otherwise.
+func dateValue(date *Date) Variable {
+	if date.Time.Location() == time.UTC {
+		return Data(date.String())
+	}
+	return typedData{Data: Data(date.String()), dataType: date.dataType(), date: date}
+}
+
+// castValue converts the value to the type. Floats are rounded to integers,
+// integers become floats, timestamps are truncated to dates and dates become
+// the timestamps of their midnight. Booleans are written as true or false,
+// so the numbers can be booleans if they are their spellings. The second result is false if the value
+// cannot be converted, e.g. N/A is not a number. NULL stays NULL.
+func castValue(value Variable, dataType string) (Variable, bool) {
+	if isNull(value) {
+		return Data(""), true
+	}
+	// The string is converted by its content, so the STRING 007 can become the number.
+	if value.defineType() == typeString {
+		value = Data(value.String())
+	}
+	convert, ok := converters[dataType]
+	if !ok {
+		return Data(""), false
+	}
+	return convert(value)
+}
+
+// converters convert the values which are not NULL to the types, see castValue.
+var converters = map[string]func(value Variable) (Variable, bool){
+	typeString:    castString,
+	typeInteger:   castInteger,
+	typeFloat:     castFloat,
+	typeDate:      castDate,
+	typeTimestamp: castTimestamp,
+	typeBool:      castBool,
+}
+
+func castString(value Variable) (Variable, bool) {
+	return typedData{Data: Data(value.String()), dataType: typeString}, true
+}
+
+func castInteger(value Variable) (Variable, bool) {
+	switch {
+	case value.isInteger():
+		return typedData{Data: Data(strconv.Itoa(value.toInteger())), dataType: typeInteger}, true
+	case value.isFloat():
+		str := strconv.FormatFloat(math.Round(value.toFloat())+0, 'f', 0, 64)
+		return typedData{Data: Data(str), dataType: typeInteger}, true
+	}
+	return Data(""), false
+}
+
+func castFloat(value Variable) (Variable, bool) {
+	if !value.isFloat() {
+		return Data(""), false
+	}
+	return typedData{Data: Data(formatFloat(value.toFloat())), dataType: typeFloat}, true
+}
+
+func castDate(value Variable) (Variable, bool) {
+	if !isTime(value.defineType()) {
+		return Data(""), false
+	}
+	date := value.toDate().day()
+	return typedData{Data: Data(date.String()), dataType: typeDate, date: date}, true
+}
+
+func castTimestamp(value Variable) (Variable, bool) {
+	if !isTime(value.defineType()) {
+		return Data(""), false
+	}
+	date := &Date{Time: value.toDate().Time, Timestamp: true}
+	return typedData{Data: Data(date.String()), dataType: typeTimestamp, date: date}, true
+}
+
+func castBool(value Variable) (Variable, bool) {
+	if !value.isBool() {
+		return Data(""), false
+	}
+	truth := value.toBool()
+	return typedData{Data: Data(strconv.FormatBool(truth)), dataType: typeBool, truth: truth}, true
+}
+
+// Cast converts the value of the expression to the type, e.g. CAST(new_cases AS INT).
+// The result is NULL if the value cannot be converted.
+type Cast struct {
+	Expr Expression
+	Type string
+}
+
+// Eval returns the converted value of the expression for the row.
+func (c *Cast) Eval(row Record) Variable {
+	value, _ := castValue(c.Expr.Eval(row), c.Type)
+	return value
+}
+
+// GetFields returns fields used in the expression.
+func (c *Cast) GetFields() []string {
+	return c.Expr.GetFields()
+}
+
+// String returns the CAST expression as it is written in the request.
+func (c *Cast) String() string {
+	return fmt.Sprintf("%s(%s %s %s)", kwCast, c.Expr, kwAs, c.Type)
+}
@@ -0,0 +1,118 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCastValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		dataType string
+		result   string
+		ok       bool
+	}{
+		{name: "floatToInteger", value: "4268.0", dataType: typeInteger, result: "4268", ok: true},
+		{name: "roundedToInteger", value: "4.5", dataType: typeInteger, result: "5", ok: true},
+		{name: "negativeZero", value: "-0.4", dataType: typeInteger, result: "0", ok: true},
+		{name: "integerToFloat", value: "4", dataType: typeFloat, result: "4.0", ok: true},
+		{name: "numberToString", value: "007", dataType: typeString, result: "007", ok: true},
+		{name: "timestampToDate", value: "2020-04-20 15:30:00", dataType: typeDate, result: "2020-04-20", ok: true},
+		{name: "dateToTimestamp", value: "2020-04-20", dataType: typeTimestamp, result: "2020-04-20T00:00:00Z", ok: true},
+		{name: "null", value: "", dataType: typeInteger, result: "", ok: true},
+		{name: "notNumber", value: "N/A", dataType: typeFloat, result: "", ok: false},
+		{name: "notDate", value: "2020-02-30", dataType: typeDate, result: "", ok: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			value, ok := castValue(Data(tc.value), tc.dataType)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.result, value.String())
+			if tc.ok && tc.result != "" {
+				assert.Equal(t, tc.dataType, value.defineType())
+			}
+		})
+	}
+}
+
+func TestCastCompare(t *testing.T) {
+	code := &Cast{Expr: &Column{Name: "code"}, Type: typeString}
+	row := RowData{"code": "007"}
+
+	seven := &Literal{Value: Data("7")}
+	assert.Equal(t, True, (&Criterion{Left: &Column{Name: "code"}, Right: seven, Symbol: equal}).Check(row))
+	assert.Equal(t, False, (&Criterion{Left: code, Right: seven, Symbol: equal}).Check(row))
+	assert.Equal(t, True, (&Criterion{Left: code, Right: &Literal{Value: Data("007")}, Symbol: equal}).Check(row))
+
+	in := newIn(code, false)
+	in.add(Data("7"), false)
+	assert.Equal(t, False, in.Check(row))
+}
+
+func TestCastString(t *testing.T) {
+	half := &Arithmetic{Left: &Column{Name: "new_cases"}, Right: &Literal{Value: Data("2")}, Operator: divide}
+	cast := &Cast{Expr: half, Type: typeInteger}
+	assert.Equal(t, "CAST(new_cases / 2 AS INT)", cast.String())
+	assert.Equal(t, []string{"new_cases"}, cast.GetFields())
+	assert.Equal(t, typedData{Data: Data("3"), dataType: typeInteger}, cast.Eval(RowData{"new_cases": "5"}))
+}
+
+func TestRequestDoBool(t *testing.T) {
+	options := &Options{TrueValues: []string{"yes", "1"}, FalseValues: []string{"no", "0"}}
+
+	tests := []struct {
+		name   string
+		str    string
+		expect []string
+	}{
+		{
+			name:   "trueLiteral",
+			str:    "SELECT name FROM ./test/flags.csv WHERE active = TRUE",
+			expect: []string{"a", "c"},
+		},
+		{
+			name:   "falseWord",
+			str:    "SELECT name FROM ./test/flags.csv WHERE active = no",
+			expect: []string{"b", "d"},
+		},
+		{
+			name:   "numbersAreNotBools",
+			str:    "SELECT name FROM ./test/flags.csv WHERE verified = TRUE",
+			expect: []string{},
+		},
+		{
+			name:   "castNumbers",
+			str:    "SELECT name FROM ./test/flags.csv WHERE CAST(verified AS BOOL) = TRUE",
+			expect: []string{"a", "d"},
+		},
+		{
+			name:   "in",
+			str:    "SELECT name FROM ./test/flags.csv WHERE active IN (TRUE)",
+			expect: []string{"a", "c"},
+		},
+		{
+			name:   "order",
+			str:    "SELECT name FROM ./test/flags.csv ORDER BY active DESC, name",
+			expect: []string{"a", "c", "b", "d"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest(tc.str, options)
+			assert.NoError(t, err)
+			result, err := req.Do(context.Background(), ",")
+			assert.NoError(t, err)
+
+			names := make([]string, len(result.Data))
+			for ind, data := range result.Data {
+				names[ind] = data["name"]
+			}
+			assert.Equal(t, tc.expect, names)
+		})
+	}
+}
@@ -1,100 +1,310 @@
 package request
 
-// Condition represents a wrapper on two main possible conditions.
-// Only one condition option can be used at a time.
-// Thus, it represents next criterion in the chain of options.
-type Condition struct {
-	And *Criterion
-	Or  *Criterion
-}
-
-// GetExist returns Criterion of the Condition.
-func (c *Condition) GetExist() *Criterion {
-	if c != nil {
-		if c.And != nil {
-			return c.And
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record gives access to the values of the row by the field names.
+// It is implemented by the csv lines and by the rows of results.
+type Record interface {
+	Get(field string) string
+}
+
+// Condition is a node of the WHERE or HAVING expression tree.
+// It checks if the whole row satisfies the requirements.
+type Condition interface {
+	Check(row Record) Truth
+	GetFields() []string
+	String() string
+}
+
+// Logical joins two conditions with AND or OR operator.
+type Logical struct {
+	Left     Condition
+	Right    Condition
+	Operator string
+}
+
+// Check evaluates both sides of the Logical and combines the results.
+// The right side is not evaluated if the left one defines the result.
+func (l *Logical) Check(row Record) Truth {
+	left := l.Left.Check(row)
+	if l.Operator == and {
+		if left == False {
+			return False
 		}
-		if c.Or != nil {
-			return c.Or
+		if right := l.Right.Check(row); right < left {
+			return right
+		}
+		return left
+	}
+
+	if left == True {
+		return True
+	}
+	if right := l.Right.Check(row); right > left {
+		return right
+	}
+	return left
+}
+
+// GetFields returns unique fields used in both sides of the Logical.
+func (l *Logical) GetFields() []string {
+	fields := l.Left.GetFields()
+	for _, field := range l.Right.GetFields() {
+		if !sliceHasString(field, fields) {
+			fields = append(fields, field)
 		}
 	}
-	return nil
+	return fields
 }
 
-// Criterion combines all information about WHERE option.
-// It contains its value and definition symbol and name of the field.
-// Also it has a hook to the next criterion.
+// String returns the Logical as it is written in the request.
+// OR operands of AND are wrapped in brackets to keep the order of evaluation.
+func (l *Logical) String() string {
+	left, right := l.Left.String(), l.Right.String()
+	if l.Operator == and {
+		left, right = wrapOr(l.Left), wrapOr(l.Right)
+	}
+	return fmt.Sprintf("%s %s %s", left, l.Operator, right)
+}
+
+// wrapOr wraps the condition in brackets if it is joined with OR operator.
+func wrapOr(cond Condition) string {
+	if logical, ok := cond.(*Logical); ok && logical.Operator == or {
+		return "(" + logical.String() + ")"
+	}
+	return cond.String()
+}
+
+// Negation inverts the result of the condition. Unknown stays Unknown.
+type Negation struct {
+	Condition Condition
+}
+
+// Check evaluates the condition and inverts its result.
+func (n *Negation) Check(row Record) Truth {
+	// Truth values are symmetric around Unknown.
+	return True - n.Condition.Check(row)
+}
+
+// GetFields returns fields used in the condition.
+func (n *Negation) GetFields() []string {
+	return n.Condition.GetFields()
+}
+
+// String returns the Negation as it is written in the request.
+func (n *Negation) String() string {
+	if _, ok := n.Condition.(*Logical); ok {
+		return fmt.Sprintf("%s (%s)", not, n.Condition)
+	}
+	return fmt.Sprintf("%s %s", not, n.Condition)
+}
+
+// Criterion compares the values of two expressions with the Symbol.
+// The Left side is usually the field of the file and the Right side is its value.
+// Unless CaseSensitive is set, values of both sides are lowered before comparison.
 type Criterion struct {
-	Value      Variable
-	Conditions *Condition
-	Field      string
-	Symbol     string
-	Strict     bool
+	Left          Expression
+	Right         Expression
+	Symbol        string
+	CaseSensitive bool
 }
 
-// GetFields returns map, representing SELECT fields.
+// GetFields returns unique fields used in both sides of the Criterion.
 func (c *Criterion) GetFields() []string {
-	crit := c
-	var fields []string
-	fields = append(fields, crit.Field)
-
-	for {
-		crit = crit.Conditions.GetExist()
-		if crit == nil {
-			break
-		}
-		if !sliceHasString(crit.Field, fields) {
-			fields = append(fields, crit.Field)
+	fields := c.Left.GetFields()
+	for _, field := range c.Right.GetFields() {
+		if !sliceHasString(field, fields) {
+			fields = append(fields, field)
 		}
 	}
-
 	return fields
 }
 
-func (c *Criterion) getLastCondition() *Criterion {
-	if c.Conditions == nil {
-		return c
+// String returns the Criterion as it is written in the request.
+func (c *Criterion) String() string {
+	return fmt.Sprintf("%s %s %s", c.Left, c.Symbol, c.Right)
+}
+
+// Check compares the value of the Left side in the row with the value of the Right side.
+// The result is Unknown if any side is NULL.
+func (c *Criterion) Check(row Record) Truth {
+	lineValue, value := c.Left.Eval(row), c.Right.Eval(row)
+	if isNull(lineValue) || isNull(value) {
+		return Unknown
 	}
-	crit := c.Conditions.GetExist()
-	for {
-		if crit.Conditions == nil {
-			return crit
-		}
-		crit = crit.Conditions.GetExist()
+	// Dates and timestamps are not lowered, since their layouts can be case sensitive.
+	if !c.CaseSensitive && lineValue.defineType() == typeString {
+		lineValue = lowerValue(lineValue)
+		value = lowerValue(value)
+	}
+	if !canCompare(value, lineValue) {
+		return Unknown
 	}
+	return truth(analyze(c.Symbol, value, lineValue))
 }
 
-func (c *Criterion) Condition(key, lineData string) bool {
-	var result bool
-	lineValue := Data(removeCharacters(lineData, " "))
+// lowerValue returns the value in lower case keeping its declared type.
+func lowerValue(value Variable) Variable {
+	lowered := Data(strings.ToLower(value.String()))
+	if typed, ok := value.(typedData); ok {
+		typed.Data = lowered
+		return typed
+	}
+	return lowered
+}
 
-	crit := c
-	for {
-		if crit.Field == key {
-			if !crit.Strict && !result || crit.Strict {
-				result = analyze(crit.Symbol, crit.Value, lineValue)
-			}
-			// if !crit.Strict && result {
-			// 	continue
-			// }
-			if crit.Strict && !result {
-				return result
-			}
+// In checks if the value of the expression is one of the values of the list.
+// The list is compiled into the set once, so the check does not depend on its length.
+// Quoted values are matched exactly and bare words ignoring the case of letters.
+type In struct {
+	Expr Expression
+	// set maps the normalized and the original values to their case sensitivity.
+	set map[string]bool
+	// values are kept as they are written in the request.
+	values []string
+	Not    bool
+}
+
+// newIn returns In condition for the expression with an empty list of values.
+func newIn(expr Expression, negated bool) *In {
+	return &In{Expr: expr, set: make(map[string]bool), Not: negated}
+}
+
+// add adds the value to the list.
+func (in *In) add(value Variable, caseSensitive bool) {
+	if caseSensitive {
+		in.values = append(in.values, (&Literal{Value: value}).String())
+	} else {
+		in.values = append(in.values, value.String())
+	}
+
+	// The value is also kept as it is for the values of the declared STRING type.
+	for _, key := range []string{setKey(value), value.String()} {
+		if !caseSensitive {
+			key = strings.ToLower(key)
 		}
-		crit = crit.Conditions.GetExist()
-		if crit != nil {
+		// The bare word matches more values than the quoted one, so it is kept.
+		if caseSensitive, ok := in.set[key]; ok && !caseSensitive {
 			continue
 		}
-		break
+		in.set[key] = caseSensitive
 	}
+}
 
-	return result
+// GetFields returns fields used in the expression.
+func (in *In) GetFields() []string {
+	return in.Expr.GetFields()
+}
+
+// String returns the In condition as it is written in the request.
+func (in *In) String() string {
+	return fmt.Sprintf("%s %s (%s)", in.Expr, negatedKeyword(kwIn, in.Not), strings.Join(in.values, ", "))
+}
+
+// negatedKeyword prefixes the keyword with NOT if the condition is negated.
+func negatedKeyword(keyword string, negated bool) string {
+	if negated {
+		return not + " " + keyword
+	}
+	return keyword
+}
+
+// Check defines if the value of the expression in the row is in the list.
+// The result is Unknown if the value is NULL.
+func (in *In) Check(row Record) Truth {
+	value := in.Expr.Eval(row)
+	if isNull(value) {
+		return Unknown
+	}
+
+	key := setKey(value)
+	found := in.set[key]
+	if !found {
+		caseSensitive, ok := in.set[strings.ToLower(key)]
+		found = ok && !caseSensitive
+	}
+	return truth(found != in.Not)
+}
+
+// setKey normalizes numbers, booleans and moments of time, so 540 and 540.0, yes and true
+// or the same time in different layouts and time zones are the same value of the set.
+func setKey(value Variable) string {
+	if value.isFloat() {
+		return formatFloat(value.toFloat())
+	}
+	if isTime(value.defineType()) {
+		return value.toDate().Time.UTC().Format(time.RFC3339Nano)
+	}
+	if value.isBool() {
+		return strconv.FormatBool(value.toBool())
+	}
+	return value.String()
+}
+
+// Between checks if the value of the expression is within the bounds inclusively.
+// Numbers and dates are compared by their types, see analyze.
+type Between struct {
+	Expr Expression
+	Low  Expression
+	High Expression
+	Not  bool
+}
+
+// GetFields returns unique fields used in the expression and in the bounds.
+func (b *Between) GetFields() []string {
+	fields := b.Expr.GetFields()
+	for _, expr := range []Expression{b.Low, b.High} {
+		for _, field := range expr.GetFields() {
+			if !sliceHasString(field, fields) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// String returns the Between condition as it is written in the request.
+func (b *Between) String() string {
+	return fmt.Sprintf("%s %s %s %s %s", b.Expr, negatedKeyword(kwBetween, b.Not), b.Low, and, b.High)
+}
+
+// Check defines if the value of the expression in the row is between the bounds.
+// The result is Unknown if the value or any of the bounds is NULL or cannot be compared.
+func (b *Between) Check(row Record) Truth {
+	lineValue, low, high := b.Expr.Eval(row), b.Low.Eval(row), b.High.Eval(row)
+	if isNull(lineValue) || isNull(low) || isNull(high) || !canCompare(low, lineValue) || !canCompare(high, lineValue) {
+		return Unknown
+	}
+	result := analyze(greaterOrEqual, low, lineValue) && analyze(lessOrEqual, high, lineValue)
+	return truth(result != b.Not)
+}
+
+// canCompare defines if the values have the same type, so 0 is not compared with xyz.
+// Integers and floats are numbers and dates and timestamps are moments of time.
+// The value of the declared STRING type is compared as text with any value.
+func canCompare(value, lineData Variable) bool {
+	valueType, lineType := value.defineType(), lineData.defineType()
+	switch {
+	case valueType == lineType, isNumber(valueType) && isNumber(lineType), isTime(valueType) && isTime(lineType):
+		return true
+	}
+	return isDeclaredString(value) || isDeclaredString(lineData)
+}
+
+func isDeclaredString(value Variable) bool {
+	typed, ok := value.(typedData)
+	return ok && typed.dataType == typeString
 }
 
 func analyze(symbol string, data, lineData Variable) bool {
 	var result bool
 	switch symbol {
-	case "NOT":
+	case "!=":
 		result = checkNot(data, lineData)
 	case "=":
 		result = checkEqual(data, lineData)
@@ -110,142 +320,28 @@ func analyze(symbol string, data, lineData Variable) bool {
 	return result
 }
 
+// checkNot and other check functions compare the value of the row with the value
+// of the condition the same way as the rows are sorted, see compareValues.
 func checkNot(value, lineData Variable) bool {
-	switch lineData.defineType() {
-	case typeInteger:
-		intValue := value.toInteger()
-		intLineData := lineData.toInteger()
-		if intValue != intLineData {
-			return true
-		}
-	case typeFloat:
-		floatValue := value.toFloat()
-		floatLineData := lineData.toFloat()
-		if floatValue != floatLineData {
-			return true
-		}
-	case typeString:
-		if value != lineData {
-			return true
-		}
-	case typeDate:
-		dateValue := value.toDate()
-		dateLineData := lineData.toDate()
-		return dateValue.Not(dateLineData)
-	}
-	return false
+	return compareValues(lineData, value) != 0
 }
 
 func checkEqual(value, lineData Variable) bool {
-	switch lineData.defineType() {
-	case typeInteger:
-		intValue := value.toInteger()
-		intLineData := lineData.toInteger()
-		if intValue == intLineData {
-			return true
-		}
-	case typeFloat:
-		floatValue := value.toFloat()
-		floatLineData := lineData.toFloat()
-		if floatValue == floatLineData {
-			return true
-		}
-	case typeString:
-		if value == lineData {
-			return true
-		}
-	case typeDate:
-		dateValue := value.toDate()
-		dateLineData := lineData.toDate()
-		return dateValue.Equal(dateLineData)
-	}
-	return false
+	return compareValues(lineData, value) == 0
 }
 
 func checkGreater(value, lineData Variable) bool {
-	switch lineData.defineType() {
-	case typeInteger:
-		intValue := value.toInteger()
-		intLineData := lineData.toInteger()
-		if intValue < intLineData {
-			return true
-		}
-	case typeFloat:
-		floatValue := value.toFloat()
-		floatLineData := lineData.toFloat()
-		if floatValue < floatLineData {
-			return true
-		}
-	case typeDate:
-		dateValue := value.toDate()
-		dateLineData := lineData.toDate()
-		return dateLineData.Greater(dateValue)
-	}
-	return false
+	return compareValues(lineData, value) > 0
 }
 
 func checkLess(value, lineData Variable) bool {
-	switch lineData.defineType() {
-	case typeInteger:
-		intValue := value.toInteger()
-		intLineData := lineData.toInteger()
-		if intValue > intLineData {
-			return true
-		}
-	case typeFloat:
-		floatValue := value.toFloat()
-		floatLineData := lineData.toFloat()
-		if floatValue > floatLineData {
-			return true
-		}
-	case typeDate:
-		dateValue := value.toDate()
-		dateLineData := lineData.toDate()
-		return dateLineData.Less(dateValue)
-	}
-	return false
+	return compareValues(lineData, value) < 0
 }
 
 func checkGreaterOrEqual(value, lineData Variable) bool {
-	switch lineData.defineType() {
-	case typeInteger:
-		intValue := value.toInteger()
-		intLineData := lineData.toInteger()
-		if intValue <= intLineData {
-			return true
-		}
-	case typeFloat:
-		floatValue := value.toFloat()
-		floatLineData := lineData.toFloat()
-		if floatValue <= floatLineData {
-			return true
-		}
-	case typeDate:
-		dateValue := value.toDate()
-		dateLineData := lineData.toDate()
-		return dateLineData.GreaterOrEqual(dateValue)
-	}
-	return false
+	return compareValues(lineData, value) >= 0
 }
 
 func checkLessOrEqual(value, lineData Variable) bool {
-	switch lineData.defineType() {
-	case typeInteger:
-		intValue := value.toInteger()
-		intLineData := lineData.toInteger()
-		if intValue >= intLineData {
-			return true
-		}
-	case typeFloat:
-		floatValue := value.toFloat()
-		floatLineData := lineData.toFloat()
-		if floatValue >= floatLineData {
-			return true
-		}
-	case typeDate:
-		dateValue := value.toDate()
-		dateLineData := lineData.toDate()
-		return dateLineData.LessOrEqual(dateValue)
-	}
-	return false
+	return compareValues(lineData, value) <= 0
 }
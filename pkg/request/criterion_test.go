@@ -6,45 +6,223 @@ import (
 	"github.com/magiconair/properties/assert"
 )
 
-var TestCriterion = &Criterion{
-	Field: "level0",
-	Conditions: &Condition{
-		And: &Criterion{
-			Field: "level1",
-			Conditions: &Condition{
-				Or: &Criterion{
-					Field: "level2",
-					Conditions: &Condition{
-						Or: &Criterion{
-							Field: "level0",
-						},
-					},
-				},
-			},
-		},
+var TestCondition = &Logical{
+	Operator: and,
+	Left: &Logical{
+		Operator: or,
+		Left:     &Criterion{Left: &Column{Name: "level0"}, Symbol: equal, Right: &Literal{Value: Data("a")}},
+		Right:    &Criterion{Left: &Column{Name: "level1"}, Symbol: equal, Right: &Literal{Value: Data("b")}},
+	},
+	Right: &Logical{
+		Operator: or,
+		Left:     &Criterion{Left: &Column{Name: "level2"}, Symbol: greater, Right: &Literal{Value: Data("5")}},
+		Right:    &Criterion{Left: &Column{Name: "level0"}, Symbol: equal, Right: &Literal{Value: Data("c")}},
 	},
 }
 
-func TestConditionGetExist(t *testing.T) {
+func TestLogicalCheck(t *testing.T) {
+	index := IndexMap{"level0": 0, "level1": 1, "level2": 2}
 	tests := []struct {
-		expect    *Criterion
-		condition *Condition
-		name      string
+		name   string
+		line   []string
+		result bool
 	}{
-		{name: "getAnd", condition: &Condition{And: TestCriterion}, expect: TestCriterion},
-		{name: "getOr", condition: &Condition{Or: TestCriterion}, expect: TestCriterion},
-		{name: "getNil", condition: &Condition{}, expect: nil},
+		{name: "bothSides", line: []string{"a", "x", "10"}, result: true},
+		{name: "rightOfOr", line: []string{"c", "b", "0"}, result: true},
+		{name: "leftFails", line: []string{"c", "x", "10"}, result: false},
+		{name: "rightFails", line: []string{"a", "b", "1"}, result: false},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			cond := tc.condition.GetExist()
-			assert.Equal(t, cond, tc.expect)
+			result := TestCondition.Check(Row{Index: index, Line: tc.line}) == True
+			assert.Equal(t, result, tc.result)
 		})
 	}
 }
 
 func TestCriterionGetFields(t *testing.T) {
-	fields := TestCriterion.GetFields()
+	fields := TestCondition.GetFields()
 	assert.Equal(t, fields, []string{"level0", "level1", "level2"})
 }
+
+func TestCriterionCheckSpaces(t *testing.T) {
+	crit := &Criterion{Left: &Column{Name: "continent"}, Symbol: equal, Right: &Literal{Value: Data("north america")}}
+	index := IndexMap{"continent": 0}
+
+	assert.Equal(t, crit.Check(Row{Index: index, Line: []string{"North America"}}), True)
+	assert.Equal(t, crit.Check(Row{Index: index, Line: []string{"NorthAmerica"}}), False)
+}
+
+func TestCriterionCheckCase(t *testing.T) {
+	index := IndexMap{"code": 0}
+	tests := []struct {
+		crit   *Criterion
+		name   string
+		line   []string
+		result bool
+	}{
+		{
+			name: "sensitiveMatch",
+			crit: &Criterion{
+				Left: &Column{Name: "code"}, Symbol: equal, Right: &Literal{Value: Data("AbC")}, CaseSensitive: true,
+			},
+			line:   []string{"AbC"},
+			result: true,
+		},
+		{
+			name: "sensitiveMismatch",
+			crit: &Criterion{
+				Left: &Column{Name: "code"}, Symbol: equal, Right: &Literal{Value: Data("AbC")}, CaseSensitive: true,
+			},
+			line:   []string{"abc"},
+			result: false,
+		},
+		{
+			name:   "insensitive",
+			crit:   &Criterion{Left: &Column{Name: "code"}, Symbol: equal, Right: &Literal{Value: Data("abc")}},
+			line:   []string{"ABC"},
+			result: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.crit.Check(Row{Index: index, Line: tc.line}) == True, tc.result)
+		})
+	}
+}
+
+func TestInCheck(t *testing.T) {
+	in := newIn(&Column{Name: "value"}, false)
+	in.add(Data("Russia"), true)
+	in.add(Data("united states"), false)
+	in.add(Data("540"), false)
+	index := IndexMap{"value": 0}
+
+	tests := []struct {
+		name   string
+		value  string
+		result bool
+	}{
+		{name: "quoted", value: "Russia", result: true},
+		{name: "quotedCase", value: "RUSSIA", result: false},
+		{name: "bareWord", value: "United States", result: true},
+		{name: "number", value: "540.0", result: true},
+		{name: "missing", value: "Ukraine", result: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			row := Row{Index: index, Line: []string{tc.value}}
+			assert.Equal(t, in.Check(row), truth(tc.result))
+
+			in.Not = true
+			assert.Equal(t, in.Check(row), truth(!tc.result))
+			in.Not = false
+		})
+	}
+}
+
+func TestBetweenCheck(t *testing.T) {
+	index := IndexMap{"value": 0}
+	tests := []struct {
+		name   string
+		low    string
+		high   string
+		value  string
+		result bool
+	}{
+		{name: "integer", low: "1", high: "10", value: "10", result: true},
+		{name: "integerOutside", low: "1", high: "10", value: "11", result: false},
+		{name: "float", low: "0.5", high: "1.5", value: "0.5", result: true},
+		{name: "floatOutside", low: "0.5", high: "1.5", value: "1.51", result: false},
+		{name: "date", low: "2020-04-20", high: "2020-04-30", value: "2020-04-20", result: true},
+		{name: "dateOutside", low: "2020-04-20", high: "2020-04-30", value: "2020-05-01", result: false},
+		{name: "integerFloatBounds", low: "2.5", high: "3.5", value: "3", result: true},
+		{name: "integerBelowFloat", low: "4.5", high: "20", value: "3", result: false},
+		{name: "floatIntegerBounds", low: "4", high: "5", value: "4.5", result: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			low, high := &Literal{Value: Data(tc.low)}, &Literal{Value: Data(tc.high)}
+			between := &Between{Expr: &Column{Name: "value"}, Low: low, High: high}
+			row := Row{Index: index, Line: []string{tc.value}}
+			assert.Equal(t, between.Check(row), truth(tc.result))
+
+			between.Not = true
+			assert.Equal(t, between.Check(row), truth(!tc.result))
+		})
+	}
+}
+
+func TestCriterionCheckMixedNumbers(t *testing.T) {
+	index := IndexMap{"value": 0}
+	tests := []struct {
+		name   string
+		symbol string
+		bound  string
+		value  string
+		result bool
+	}{
+		{name: "greaterThanFloat", symbol: greater, bound: "4.5", value: "3", result: false},
+		{name: "lessThanFloat", symbol: less, bound: "4.5", value: "3", result: true},
+		{name: "greaterOrEqualFloat", symbol: greaterOrEqual, bound: "2.5", value: "3", result: true},
+		{name: "lessOrEqualFloat", symbol: lessOrEqual, bound: "2.5", value: "3", result: false},
+		{name: "equalWholeFloat", symbol: equal, bound: "3.0", value: "3", result: true},
+		{name: "notEqualFloat", symbol: notEqual, bound: "3.5", value: "3", result: true},
+		{name: "floatGreaterThanInteger", symbol: greater, bound: "4", value: "4.5", result: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cond := &Criterion{Left: &Column{Name: "value"}, Symbol: tc.symbol, Right: &Literal{Value: Data(tc.bound)}}
+			row := Row{Index: index, Line: []string{tc.value}}
+			assert.Equal(t, cond.Check(row), truth(tc.result))
+		})
+	}
+}
+
+func TestCriterionCheckMismatch(t *testing.T) {
+	index := IndexMap{"value": 0}
+	tests := []struct {
+		name   string
+		symbol string
+		bound  Variable
+		value  string
+		result Truth
+	}{
+		{name: "numberAndWord", symbol: equal, bound: Data("xyz"), value: "0", result: Unknown},
+		{name: "wordAndNumber", symbol: notEqual, bound: Data("5"), value: "Russia", result: Unknown},
+		{name: "numberAndDate", symbol: less, bound: Data("2020-04-20"), value: "3", result: Unknown},
+		{
+			name:   "declaredString",
+			symbol: greater,
+			bound:  typedData{Data: Data("2"), dataType: typeString},
+			value:  "10",
+			result: False,
+		},
+		{name: "dateAndTimestamp", symbol: less, bound: Data("2020-04-20T10:00:00Z"), value: "2020-04-20", result: True},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cond := &Criterion{Left: &Column{Name: "value"}, Symbol: tc.symbol, Right: &Literal{Value: tc.bound}}
+			row := Row{Index: index, Line: []string{tc.value}}
+			assert.Equal(t, cond.Check(row), tc.result)
+		})
+	}
+
+	between := &Between{Expr: &Column{Name: "value"}, Low: &Literal{Value: Data("a")}, High: &Literal{Value: Data("z")}}
+	assert.Equal(t, between.Check(Row{Index: index, Line: []string{"3"}}), Unknown)
+}
+
+func TestNegationCheck(t *testing.T) {
+	index := IndexMap{"level0": 0, "level1": 1, "level2": 2}
+	negation := &Negation{Condition: TestCondition}
+
+	assert.Equal(t, negation.Check(Row{Index: index, Line: []string{"a", "x", "10"}}), False)
+	assert.Equal(t, negation.Check(Row{Index: index, Line: []string{"c", "x", "10"}}), True)
+	assert.Equal(t, negation.Check(Row{Index: index, Line: []string{"a", "x", ""}}), Unknown)
+}
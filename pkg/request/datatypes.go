@@ -1,103 +1,117 @@
 package request
 
 import (
-	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	typeInteger = "INT"
-	typeFloat   = "FLOAT"
-	typeString  = "STRING"
-	typeDate    = "DATE"
+	typeInteger   = "INT"
+	typeFloat     = "FLOAT"
+	typeString    = "STRING"
+	typeDate      = "DATE"
+	typeTimestamp = "TIMESTAMP"
+	typeBool      = "BOOL"
 )
 
 // Variable is an interface which determine methods of the Condition Value.
 type Variable interface {
+	String() string
 	defineType() string
 	isInteger() bool
 	toInteger() int
 	isFloat() bool
 	toFloat() float64
 	isDate() bool
+	isTimestamp() bool
 	toDate() *Date
+	isBool() bool
+	toBool() bool
 }
 
-// Date is a wrapper around the date.
+const (
+	// dateLayout is the format of the dates in requests and in the results.
+	dateLayout = "2006-01-02"
+	// timestampLayout is the format of the timestamps in the results.
+	timestampLayout = time.RFC3339
+)
+
+// Date is a wrapper around the date or the timestamp, if Timestamp is set.
 // It defines methods and fields to work with dates easily.
+// Dates and timestamps are compared as the moments of time,
+// so the date is the midnight of the day.
 type Date struct {
-	Year  int
-	Month int
-	Day   int
+	Time      time.Time
+	Timestamp bool
+}
+
+// newDate returns the Date of the given day in UTC.
+func newDate(year, month, day int) *Date {
+	return &Date{Time: time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)}
+}
+
+// String returns the date in YYYY-MM-DD format or the timestamp in RFC3339 format.
+func (d *Date) String() string {
+	if d.Timestamp {
+		return d.Time.Format(timestampLayout)
+	}
+	return d.Time.Format(dateLayout)
+}
+
+// dataType returns the type of the values of the date.
+func (d *Date) dataType() string {
+	if d.Timestamp {
+		return typeTimestamp
+	}
+	return typeDate
+}
+
+// day returns the date of the timestamp in its time zone.
+func (d *Date) day() *Date {
+	year, month, day := d.Time.Date()
+	return &Date{Time: time.Date(year, month, day, 0, 0, 0, 0, d.Time.Location())}
 }
 
 // Not defines if dates are not equal.
 func (d *Date) Not(ad *Date) bool {
-	if d.Year != ad.Year || d.Month != ad.Month || d.Day != ad.Day {
-		return true
-	}
-	return false
+	return !d.Equal(ad)
 }
 
 // Equal defines if dates are Equal.
 func (d *Date) Equal(ad *Date) bool {
-	if d.Year == ad.Year && d.Month == ad.Month && d.Day == ad.Day {
-		return true
-	}
-	return false
+	return d.Time.Equal(ad.Time)
 }
 
 // Greater defines if the given date is less than the main one.
 func (d *Date) Greater(ad *Date) bool {
-	if d.Year > ad.Year {
-		return true
-	}
-	if d.Year == ad.Year && d.Month > ad.Month {
-		return true
-	}
-	if d.Year == ad.Year && d.Month == ad.Month && d.Day > ad.Day {
-		return true
-	}
-	return false
+	return d.Time.After(ad.Time)
 }
 
 // Less defines if the given date is greater than the main one.
 func (d *Date) Less(ad *Date) bool {
-	if d.Year < ad.Year {
-		return true
-	}
-	if d.Year == ad.Year && d.Month < ad.Month {
-		return true
-	}
-	if d.Year == ad.Year && d.Month == ad.Month && d.Day < ad.Day {
-		return true
-	}
-	return false
+	return d.Time.Before(ad.Time)
 }
 
 // GreaterOrEqual defines if the given date is less or equals the main one.
 func (d *Date) GreaterOrEqual(ad *Date) bool {
-	if d.Greater(ad) || d.Equal(ad) {
-		return true
-	}
-
-	return false
+	return !d.Less(ad)
 }
 
 // LessOrEqual defines if the given date is greater or equals the main one.
 func (d *Date) LessOrEqual(ad *Date) bool {
-	if d.Less(ad) || d.Equal(ad) {
-		return true
-	}
-
-	return false
+	return !d.Greater(ad)
 }
 
 // Data is a redefined custom type from string.
 // Implements Variable interface.
 type Data string
 
+// String returns the data as it is.
+func (d Data) String() string {
+	return string(d)
+}
+
 func (d Data) defineType() string {
 	if d.isInteger() {
 		return typeInteger
@@ -108,6 +122,12 @@ func (d Data) defineType() string {
 	if d.isDate() {
 		return typeDate
 	}
+	if d.isTimestamp() {
+		return typeTimestamp
+	}
+	if d.isBool() {
+		return typeBool
+	}
 	return typeString
 }
 
@@ -141,30 +161,108 @@ func (d Data) toFloat() float64 {
 	return num
 }
 
+// isDate defines if the data is a valid date in YYYY-MM-DD format, so 2020-02-30 is not a date.
+// Dates in other layouts are parsed with the formats of the request, see formats.parse.
 func (d Data) isDate() bool {
-	match, err := regexp.MatchString("[1-2][0-9][0-9][0-9]-[0-1][0-9]-[0-3][0-9]", string(d))
-	if err != nil || !match {
-		return false
-	}
-	return true
+	_, ok := defaultFormats.parseTime(string(d), defaultFormats.dateLayouts)
+	return ok
 }
 
+// isTimestamp defines if the data is a valid timestamp in RFC3339 or "YYYY-MM-DD hh:mm:ss" format.
+func (d Data) isTimestamp() bool {
+	_, ok := defaultFormats.parseTime(string(d), defaultFormats.timestampLayouts)
+	return ok
+}
+
+// toDate returns the date or the timestamp of the data.
 func (d Data) toDate() *Date {
-	dateStringSlice := strings.Split(string(d), "-")
-	dateIntSlice := stringSliceToInt(dateStringSlice)
-	return &Date{
-		Year:  dateIntSlice[0],
-		Month: dateIntSlice[1],
-		Day:   dateIntSlice[2],
+	if date, ok := defaultFormats.toDate(string(d)); ok {
+		return date
+	}
+	return &Date{Timestamp: true}
+}
+
+// isBool defines if the data is true or false in any case.
+// Other spellings are parsed with the formats of the request, see formats.parse.
+func (d Data) isBool() bool {
+	_, ok := defaultFormats.toBool(string(d))
+	return ok
+}
+
+func (d Data) toBool() bool {
+	truth, _ := defaultFormats.toBool(string(d))
+	return truth
+}
+
+// compare returns -1, 0 or 1 if the data is less, equal or greater than the given one.
+// Numbers are compared numerically, dates and timestamps chronologically,
+// false is less than true and all other values are compared lexically.
+func (d Data) compare(ad Data) int {
+	return compareValues(d, ad)
+}
+
+// compareValues compares the values by their types the same way as Data.compare,
+// so the values of the declared STRING type are always compared lexically.
+func compareValues(a, b Variable) int {
+	aType, bType := a.defineType(), b.defineType()
+	switch {
+	case aType == typeInteger && bType == typeInteger:
+		return compareFloats(float64(a.toInteger()), float64(b.toInteger()))
+	case isNumber(aType) && isNumber(bType):
+		return compareFloats(a.toFloat(), b.toFloat())
+	case isTime(aType) && isTime(bType):
+		return compareDates(a.toDate(), b.toDate())
+	case aType == typeBool && bType == typeBool:
+		return compareBools(a.toBool(), b.toBool())
 	}
+	return strings.Compare(a.String(), b.String())
 }
 
-func stringSliceToInt(s []string) []int {
-	newSlice := make([]int, len(s))
-	for ind, el := range s {
-		num, _ := strconv.Atoi(el)
-		newSlice[ind] = num
+// compareDates compares the dates and the timestamps chronologically.
+func compareDates(date, anotherDate *Date) int {
+	switch {
+	case date.Less(anotherDate):
+		return -1
+	case date.Greater(anotherDate):
+		return 1
 	}
+	return 0
+}
+
+// formatFloat formats the number the same way as floats are usually stored
+// in csv files, so whole numbers keep the fractional part, e.g. 4268.0.
+func formatFloat(num float64) string {
+	str := strconv.FormatFloat(num, 'f', -1, 64)
+	if !strings.Contains(str, ".") {
+		str += ".0"
+	}
+	return str
+}
+
+func isNumber(dataType string) bool {
+	return dataType == typeInteger || dataType == typeFloat
+}
+
+func isTime(dataType string) bool {
+	return dataType == typeDate || dataType == typeTimestamp
+}
 
-	return newSlice
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
 }
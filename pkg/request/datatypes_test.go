@@ -3,6 +3,7 @@ package request
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/magiconair/properties/assert"
 )
@@ -24,6 +25,11 @@ func TestDataDefineType(t *testing.T) {
 		{name: "int", data: testInt, expect: typeInteger},
 		{name: "float", data: testFloat, expect: typeFloat},
 		{name: "date", data: testDate, expect: typeDate},
+		{name: "invalidDate", data: Data("2020-02-30"), expect: typeString},
+		{name: "dateInString", data: Data("on 2020-11-18"), expect: typeString},
+		{name: "timestamp", data: Data("2020-11-18T10:30:00+03:00"), expect: typeTimestamp},
+		{name: "timestampWithSpace", data: Data("2020-11-18 10:30:00"), expect: typeTimestamp},
+		{name: "bool", data: Data("False"), expect: typeBool},
 	}
 
 	for _, tc := range tests {
@@ -52,13 +58,149 @@ func TestDataToFloat(t *testing.T) {
 
 func TestDataToDate(t *testing.T) {
 	date := testDate.toDate()
-	want := &Date{Year: 2020, Month: 11, Day: 18}
+	want := newDate(2020, 11, 18)
 	assert.Equal(t, date, want)
 	if !reflect.DeepEqual(date, want) {
 		t.Errorf("incorrect date: expected: %#v; got: %#v", want, date)
 	}
 }
 
+func TestDataCompare(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   Data
+		other  Data
+		expect int
+	}{
+		{name: "integers", data: Data("9"), other: Data("10"), expect: -1},
+		{name: "mixedNumbers", data: Data("10.5"), other: Data("10"), expect: 1},
+		{name: "equalNumbers", data: Data("540"), other: Data("540.0"), expect: 0},
+		{name: "dates", data: Data("2020-11-18"), other: Data("2020-02-20"), expect: 1},
+		{name: "strings", data: Data("Russia"), other: Data("Ukraine"), expect: -1},
+		{name: "numberAndString", data: Data("10"), other: Data("9a"), expect: -1},
+		{name: "bools", data: Data("true"), other: Data("FALSE"), expect: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.data.compare(tc.other), tc.expect)
+		})
+	}
+}
+
+func TestDateLayouts(t *testing.T) {
+	f := newFormats(&Options{
+		DateLayouts:      []string{"02.01.2006", "01/02/2006"},
+		TimestampLayouts: []string{"02.01.2006 15:04"},
+		Location:         time.FixedZone("MSK", 3*60*60),
+	})
+
+	tests := []struct {
+		name   string
+		data   string
+		other  string
+		expect int
+	}{
+		{name: "dotted", data: "18.11.2020", other: "2020-11-18", expect: 0},
+		{name: "slashed", data: "11/19/2020", other: "18.11.2020", expect: 1},
+		{name: "dateAndTimestamp", data: "2020-11-18", other: "18.11.2020 00:01", expect: -1},
+		{name: "midnight", data: "2020-11-18", other: "2020-11-17T21:00:00Z", expect: 0},
+		{name: "offsets", data: "2020-11-18 10:30:00", other: "2020-11-18T07:30:00Z", expect: 0},
+		{name: "timestamps", data: "18.11.2020 10:31", other: "2020-11-18T10:30:00+03:00", expect: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, compareValues(f.parse(tc.data), f.parse(tc.other)), tc.expect)
+		})
+	}
+	assert.Equal(t, f.parse("18.11.2020 10:30").defineType(), typeTimestamp)
+	assert.Equal(t, f.parse("18.11.2020 10:30").toDate().String(), "2020-11-18T10:30:00+03:00")
+	// The layouts of the request do not change Data.
+	assert.Equal(t, Data("18.11.2020").defineType(), typeString)
+}
+
+func TestInCheckDates(t *testing.T) {
+	f := newFormats(&Options{DateLayouts: []string{"02.01.2006"}})
+
+	index := IndexMap{"time": 0}
+	tests := []struct {
+		name   string
+		value  string
+		list   string
+		result Truth
+	}{
+		{name: "layouts", value: "20.04.2020", list: "2020-04-20", result: True},
+		{name: "offsets", value: "2020-04-20T10:30:00+03:00", list: "2020-04-20T07:30:00Z", result: True},
+		{name: "midnight", value: "2020-04-20T00:00:00Z", list: "2020-04-20", result: True},
+		{name: "otherDay", value: "21.04.2020", list: "2020-04-20", result: False},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			in := newIn(&Column{Name: "time", formats: f}, false)
+			in.add(f.parse(tc.list), false)
+			assert.Equal(t, in.Check(Row{Index: index, Line: []string{tc.value}}), tc.result)
+		})
+	}
+}
+
+func TestCriterionCheckTimestamp(t *testing.T) {
+	index := IndexMap{"time": 0}
+	row := Row{Index: index, Line: []string{"2020-11-18T10:30:00Z"}}
+	cond := &Criterion{Left: &Column{Name: "time"}, Symbol: greater, Right: &Literal{Value: Data("2020-11-18")}}
+	assert.Equal(t, cond.Check(row), True)
+
+	cond = &Criterion{Left: &Column{Name: "time"}, Symbol: less, Right: &Literal{Value: Data("2020-11-18 12:00:00")}}
+	assert.Equal(t, cond.Check(row), True)
+}
+
+func TestBoolValues(t *testing.T) {
+	f := newFormats(&Options{TrueValues: []string{"Yes", "1"}, FalseValues: []string{"no", "0"}})
+
+	tests := []struct {
+		name     string
+		data     string
+		dataType string
+		isBool   bool
+		toBool   bool
+	}{
+		{name: "true", data: "TRUE", dataType: typeBool, isBool: true, toBool: true},
+		{name: "yes", data: "yes", dataType: typeBool, isBool: true, toBool: true},
+		{name: "no", data: "No", dataType: typeBool, isBool: true, toBool: false},
+		{name: "number", data: "1", dataType: typeInteger, isBool: true, toBool: true},
+		{name: "string", data: "maybe", dataType: typeString, isBool: false, toBool: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			value := f.parse(tc.data)
+			assert.Equal(t, value.defineType(), tc.dataType)
+			assert.Equal(t, value.isBool(), tc.isBool)
+			assert.Equal(t, value.toBool(), tc.toBool)
+		})
+	}
+}
+
+func TestCriterionCheckBool(t *testing.T) {
+	f := newFormats(&Options{TrueValues: []string{"yes"}, FalseValues: []string{"no"}})
+
+	row := RowData{"active": "Yes", "verified": "1"}
+	cond := &Criterion{Left: &Column{Name: "active", formats: f}, Symbol: equal, Right: &Literal{Value: Data(kwTrue)}}
+	assert.Equal(t, cond.Check(row), True)
+
+	cond = &Criterion{Left: &Column{Name: "active", formats: f}, Symbol: notEqual, Right: &Literal{Value: f.parse("no")}}
+	assert.Equal(t, cond.Check(row), True)
+
+	cond = &Criterion{Left: &Column{Name: "active", formats: f}, Symbol: equal, Right: &Literal{Value: Data("maybe")}}
+	assert.Equal(t, cond.Check(row), Unknown)
+
+	// 1 is not the spelling of the boolean, so it is NULL in the field declared as BOOL.
+	verified := &Column{Name: "verified", Type: typeBool, formats: f}
+	cond = &Criterion{Left: verified, Symbol: equal, Right: &Literal{Value: Data(kwTrue)}}
+	assert.Equal(t, cond.Check(row), Unknown)
+}
+
 type TestDate struct {
 	date        *Date
 	anotherDate *Date
@@ -68,58 +210,10 @@ type TestDate struct {
 
 func TestNotDate(t *testing.T) {
 	tests := []TestDate{
-		{
-			name: "notYear",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2019,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "notMonth",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 10,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "notDay",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   17,
-			},
-			result: true},
-		{
-			name: "notNot",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: false},
+		{name: "notYear", date: newDate(2020, 11, 18), anotherDate: newDate(2019, 11, 18), result: true},
+		{name: "notMonth", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 10, 18), result: true},
+		{name: "notDay", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 17), result: true},
+		{name: "notNot", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 18), result: false},
 	}
 
 	for _, tc := range tests {
@@ -132,32 +226,8 @@ func TestNotDate(t *testing.T) {
 
 func TestEqualDate(t *testing.T) {
 	tests := []TestDate{
-		{
-			name: "equal",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "notEqual",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   17,
-			},
-			result: false},
+		{name: "equal", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 18), result: true},
+		{name: "notEqual", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 17), result: false},
 	}
 
 	for _, tc := range tests {
@@ -170,58 +240,10 @@ func TestEqualDate(t *testing.T) {
 
 func TestGreaterDate(t *testing.T) {
 	tests := []TestDate{
-		{
-			name: "year",
-			date: &Date{
-				Year:  2021,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "month",
-			date: &Date{
-				Year:  2020,
-				Month: 12,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "day",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   20,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "not",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   15,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: false},
+		{name: "year", date: newDate(2021, 11, 18), anotherDate: newDate(2020, 11, 18), result: true},
+		{name: "month", date: newDate(2020, 12, 18), anotherDate: newDate(2020, 11, 18), result: true},
+		{name: "day", date: newDate(2020, 11, 20), anotherDate: newDate(2020, 11, 18), result: true},
+		{name: "not", date: newDate(2020, 11, 15), anotherDate: newDate(2020, 11, 18), result: false},
 	}
 
 	for _, tc := range tests {
@@ -234,58 +256,10 @@ func TestGreaterDate(t *testing.T) {
 
 func TestLessDate(t *testing.T) {
 	tests := []TestDate{
-		{
-			name: "year",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2021,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "month",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 12,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "day",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   20,
-			},
-			result: true},
-		{
-			name: "not",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   15,
-			},
-			result: false},
+		{name: "year", date: newDate(2020, 11, 18), anotherDate: newDate(2021, 11, 18), result: true},
+		{name: "month", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 12, 18), result: true},
+		{name: "day", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 20), result: true},
+		{name: "not", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 15), result: false},
 	}
 
 	for _, tc := range tests {
@@ -298,71 +272,11 @@ func TestLessDate(t *testing.T) {
 
 func TestGreaterOrEqualDate(t *testing.T) {
 	tests := []TestDate{
-		{
-			name: "year",
-			date: &Date{
-				Year:  2021,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "month",
-			date: &Date{
-				Year:  2020,
-				Month: 12,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "day",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   20,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "not",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   15,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: false},
-		{
-			name: "equal",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
+		{name: "year", date: newDate(2021, 11, 18), anotherDate: newDate(2020, 11, 18), result: true},
+		{name: "month", date: newDate(2020, 12, 18), anotherDate: newDate(2020, 11, 18), result: true},
+		{name: "day", date: newDate(2020, 11, 20), anotherDate: newDate(2020, 11, 18), result: true},
+		{name: "not", date: newDate(2020, 11, 15), anotherDate: newDate(2020, 11, 18), result: false},
+		{name: "equal", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 18), result: true},
 	}
 
 	for _, tc := range tests {
@@ -375,71 +289,11 @@ func TestGreaterOrEqualDate(t *testing.T) {
 
 func TestLessOrEqualDate(t *testing.T) {
 	tests := []TestDate{
-		{
-			name: "year",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2021,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "month",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 12,
-				Day:   18,
-			},
-			result: true},
-		{
-			name: "day",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   20,
-			},
-			result: true},
-		{
-			name: "not",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   15,
-			},
-			result: false},
-		{
-			name: "equal",
-			date: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			anotherDate: &Date{
-				Year:  2020,
-				Month: 11,
-				Day:   18,
-			},
-			result: true},
+		{name: "year", date: newDate(2020, 11, 18), anotherDate: newDate(2021, 11, 18), result: true},
+		{name: "month", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 12, 18), result: true},
+		{name: "day", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 20), result: true},
+		{name: "not", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 15), result: false},
+		{name: "equal", date: newDate(2020, 11, 18), anotherDate: newDate(2020, 11, 18), result: true},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,239 @@
+package request
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	unitSecond  string = "second"
+	unitMinute  string = "minute"
+	unitHour    string = "hour"
+	unitDay     string = "day"
+	unitWeek    string = "week"
+	unitMonth   string = "month"
+	unitQuarter string = "quarter"
+	unitYear    string = "year"
+)
+
+// now returns the current time, it is replaced in tests.
+var now = time.Now
+
+// Interval is a period of time which can be added to or subtracted from the date
+// or the timestamp, e.g. INTERVAL '7 days'. Months and years keep the day of the month
+// when possible, so 2020-01-31 + INTERVAL '1 month' is 2020-02-29. Hours, minutes
+// and seconds turn the date into the timestamp.
+type Interval struct {
+	Unit   string
+	Amount int
+}
+
+// newInterval parses the interval from the string like "7 days" or "-1 month".
+func newInterval(str string) (*Interval, error) {
+	parts := strings.Fields(strings.ToLower(str))
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("cannot parse interval: %s", str)
+	}
+	amount, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse interval: %s", str)
+	}
+
+	unit := strings.TrimSuffix(parts[1], "s")
+	switch unit {
+	case unitSecond, unitMinute, unitHour, unitDay, unitWeek, unitMonth, unitQuarter, unitYear:
+		return &Interval{Unit: unit, Amount: amount}, nil
+	}
+	return nil, fmt.Errorf("unknown unit of interval: %s", parts[1])
+}
+
+// Eval returns the interval as it is written in quotes.
+func (i *Interval) Eval(row Record) Variable {
+	return Data(i.text())
+}
+
+// GetFields returns nothing, since the interval is a constant.
+func (i *Interval) GetFields() []string {
+	return nil
+}
+
+// String returns the interval as it is written in the request.
+func (i *Interval) String() string {
+	return "INTERVAL '" + i.text() + "'"
+}
+
+func (i *Interval) text() string {
+	if i.Amount == 1 || i.Amount == -1 {
+		return fmt.Sprintf("%d %s", i.Amount, i.Unit)
+	}
+	return fmt.Sprintf("%d %ss", i.Amount, i.Unit)
+}
+
+// addTo shifts the date by the interval multiplied by the sign, which is 1 or -1.
+func (i *Interval) addTo(date *Date, sign int) *Date {
+	amount := i.Amount * sign
+	switch i.Unit {
+	case unitSecond:
+		return &Date{Time: date.Time.Add(time.Duration(amount) * time.Second), Timestamp: true}
+	case unitMinute:
+		return &Date{Time: date.Time.Add(time.Duration(amount) * time.Minute), Timestamp: true}
+	case unitHour:
+		return &Date{Time: date.Time.Add(time.Duration(amount) * time.Hour), Timestamp: true}
+	case unitDay:
+		return &Date{Time: date.Time.AddDate(0, 0, amount), Timestamp: date.Timestamp}
+	case unitWeek:
+		return &Date{Time: date.Time.AddDate(0, 0, 7*amount), Timestamp: date.Timestamp}
+	case unitMonth:
+		return addMonths(date, amount)
+	case unitQuarter:
+		return addMonths(date, 3*amount)
+	}
+	return addMonths(date, 12*amount)
+}
+
+// addMonths shifts the date by the number of months. The day is limited
+// by the length of the resulting month instead of overflowing to the next one.
+func addMonths(date *Date, months int) *Date {
+	t := date.Time
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location()).AddDate(0, months, 0)
+	day := t.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	shifted := time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return &Date{Time: shifted, Timestamp: date.Timestamp}
+}
+
+// CurrentDate is the date when the request was parsed,
+// so all rows of the request see the same date.
+type CurrentDate struct {
+	Value Variable
+}
+
+// Eval returns the current date.
+func (c *CurrentDate) Eval(row Record) Variable {
+	return c.Value
+}
+
+// GetFields returns nothing, since the current date is a constant.
+func (c *CurrentDate) GetFields() []string {
+	return nil
+}
+
+// String returns the name of the current date in the request.
+func (c *CurrentDate) String() string {
+	return kwCurrentDate
+}
+
+// dateArithmetic computes the operator if any of the values is a date or a timestamp:
+// the date plus or minus the interval or the number of days is the date
+// and the difference of two dates is the number of days between them.
+// The second result is false if the values are not dates.
+func dateArithmetic(left, right Expression, leftValue, rightValue Variable, operator string) (Variable, bool) {
+	leftDate, rightDate := isTime(leftValue.defineType()), isTime(rightValue.defineType())
+	switch {
+	case !leftDate && !rightDate:
+		return nil, false
+	case leftDate && rightDate:
+		return dateDifference(leftValue.toDate(), rightValue.toDate(), operator), true
+	case leftDate:
+		return shiftDate(leftValue.toDate(), toInterval(right, rightValue), operator), true
+	}
+	// The interval can be added to the date, but the date cannot be subtracted from it.
+	if operator != plus {
+		return Data(""), true
+	}
+	return shiftDate(rightValue.toDate(), toInterval(left, leftValue), operator), true
+}
+
+// dateDifference returns the number of days between the dates.
+// Dates can only be subtracted from each other.
+func dateDifference(date, anotherDate *Date, operator string) Variable {
+	if operator != minus {
+		return Data("")
+	}
+	return Data(strconv.Itoa(daysBetween(date, anotherDate)))
+}
+
+// shiftDate adds the interval to the date or the timestamp or subtracts it.
+// The result is NULL if the value is not the interval, see toInterval.
+func shiftDate(date *Date, interval *Interval, operator string) Variable {
+	switch {
+	case interval == nil:
+		return Data("")
+	case operator == plus:
+		return dateValue(interval.addTo(date, 1))
+	case operator == minus:
+		return dateValue(interval.addTo(date, -1))
+	}
+	return Data("")
+}
+
+// toInterval returns the interval of the expression, integers are the numbers of days.
+// It returns nil if the value cannot be added to the date.
+func toInterval(expr Expression, value Variable) *Interval {
+	if interval, ok := expr.(*Interval); ok {
+		return interval
+	}
+	if value.isInteger() {
+		return &Interval{Unit: unitDay, Amount: value.toInteger()}
+	}
+	return nil
+}
+
+// daysBetween returns the number of days from the second date to the first one.
+// Time of the timestamps is not taken into account. Days are counted in UTC,
+// so the change of the daylight saving time does not make the day shorter.
+func daysBetween(date, anotherDate *Date) int {
+	utcDay := func(d *Date) time.Time {
+		year, month, day := d.Time.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	return int(utcDay(date).Sub(utcDay(anotherDate)).Hours() / 24)
+}
+
+// datePart returns the function which extracts the part of the date as an integer.
+func datePart(part func(t time.Time) int) func(args []Variable) Variable {
+	return func(args []Variable) Variable {
+		if !isTime(args[0].defineType()) {
+			return Data("")
+		}
+		return Data(strconv.Itoa(part(args[0].toDate().Time)))
+	}
+}
+
+// dateTrunc returns the first day of the year, quarter, month or week of the date.
+// Weeks start on Monday. Timestamps are truncated to dates too.
+func dateTrunc(args []Variable) Variable {
+	if !isTime(args[1].defineType()) {
+		return Data("")
+	}
+	date := args[1].toDate().day().Time
+	year, month, day := date.Date()
+	switch strings.ToLower(args[0].String()) {
+	case unitDay:
+	case unitWeek:
+		// Weekday counts from Sunday, so it is shifted to count from Monday.
+		weekday := (int(date.Weekday()) + 6) % 7
+		return dateValue(&Date{Time: date.AddDate(0, 0, -weekday)})
+	case unitMonth:
+		day = 1
+	case unitQuarter:
+		month, day = month-(month-1)%3, 1
+	case unitYear:
+		month, day = time.January, 1
+	default:
+		return Data("")
+	}
+	return dateValue(&Date{Time: time.Date(year, month, day, 0, 0, 0, 0, date.Location())})
+}
+
+// dateDiff returns the number of days from the second date to the first one.
+func dateDiff(args []Variable) Variable {
+	if !isTime(args[0].defineType()) || !isTime(args[1].defineType()) {
+		return Data("")
+	}
+	return Data(strconv.Itoa(daysBetween(args[0].toDate(), args[1].toDate())))
+}
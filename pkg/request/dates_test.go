@@ -0,0 +1,214 @@
+package request
+
+import (
+	"context"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateArithmetic(t *testing.T) {
+	mustInterval := func(str string) *Interval {
+		interval, err := newInterval(str)
+		if err != nil {
+			t.Fatalf("error: %s", err)
+		}
+		return interval
+	}
+	date := func(str string) Expression {
+		return &Literal{Value: Data(str)}
+	}
+
+	tests := []struct {
+		left     Expression
+		right    Expression
+		name     string
+		operator string
+		result   string
+	}{
+		{name: "plusDays", left: date("2020-04-20"), right: mustInterval("7 days"), operator: plus, result: "2020-04-27"},
+		{name: "minusWeek", left: date("2020-04-20"), right: mustInterval("1 week"), operator: minus, result: "2020-04-13"},
+		{name: "endOfMonth", left: date("2020-01-31"), right: mustInterval("1 month"), operator: plus, result: "2020-02-29"},
+		{name: "minusMonth", left: date("2020-03-31"), right: mustInterval("1 month"), operator: minus, result: "2020-02-29"},
+		{name: "quarter", left: date("2020-11-30"), right: mustInterval("1 quarter"), operator: plus, result: "2021-02-28"},
+		{name: "leapYear", left: date("2020-02-29"), right: mustInterval("1 year"), operator: plus, result: "2021-02-28"},
+		{
+			name:     "intervalFirst",
+			left:     mustInterval("-2 days"),
+			right:    date("2020-04-20"),
+			operator: plus,
+			result:   "2020-04-18",
+		},
+		{name: "plusInteger", left: date("2020-04-20"), right: date("3"), operator: plus, result: "2020-04-23"},
+		{name: "integerFirst", left: date("12"), right: date("2020-04-20"), operator: plus, result: "2020-05-02"},
+		{name: "difference", left: date("2020-04-30"), right: date("2020-04-20"), operator: minus, result: "10"},
+		{name: "negativeDifference", left: date("2019-04-20"), right: date("2020-04-20"), operator: minus, result: "-366"},
+		{name: "multiply", left: date("2020-04-20"), right: date("2"), operator: multiply, result: ""},
+		{name: "plusFloat", left: date("2020-04-20"), right: date("2.5"), operator: plus, result: ""},
+		{
+			name:     "hours",
+			left:     date("2020-04-20"),
+			right:    mustInterval("36 hours"),
+			operator: plus,
+			result:   "2020-04-21T12:00:00Z",
+		},
+		{
+			name:     "timestampMonth",
+			left:     date("2020-01-31T10:00:00Z"),
+			right:    mustInterval("1 month"),
+			operator: plus,
+			result:   "2020-02-29T10:00:00Z",
+		},
+		{
+			name:     "timestampDifference",
+			left:     date("2020-04-21T01:00:00Z"),
+			right:    date("2020-04-20 23:00:00"),
+			operator: minus,
+			result:   "1",
+		},
+		{name: "intervalToNumber", left: date("5"), right: mustInterval("7 days"), operator: plus, result: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expr := &Arithmetic{Left: tc.left, Right: tc.right, Operator: tc.operator}
+			assert.Equal(t, Data(tc.result), expr.Eval(RowData{}))
+		})
+	}
+}
+
+func TestNewIntervalError(t *testing.T) {
+	_, err := newInterval("7 fortnights")
+	assert.EqualError(t, err, "unknown unit of interval: fortnights")
+
+	_, err = newInterval("seven days")
+	assert.EqualError(t, err, "cannot parse interval: seven days")
+}
+
+func TestDateFunctions(t *testing.T) {
+	call := func(name string, args ...string) *Function {
+		f := &Function{Name: name}
+		for _, arg := range args {
+			f.Args = append(f.Args, &Literal{Value: Data(arg)})
+		}
+		return f
+	}
+
+	tests := []struct {
+		call   *Function
+		name   string
+		result string
+	}{
+		{name: "year", call: call("YEAR", "2020-04-23"), result: "2020"},
+		{name: "month", call: call("MONTH", "2020-04-23"), result: "4"},
+		{name: "day", call: call("DAY", "2020-04-23"), result: "23"},
+		{name: "invalidDate", call: call("YEAR", "2020-02-30"), result: ""},
+		{name: "truncWeek", call: call("DATE_TRUNC", "week", "2020-04-23"), result: "2020-04-20"},
+		{name: "truncSunday", call: call("DATE_TRUNC", "week", "2020-04-26"), result: "2020-04-20"},
+		{name: "truncMonth", call: call("DATE_TRUNC", "MONTH", "2020-04-23"), result: "2020-04-01"},
+		{name: "truncQuarter", call: call("DATE_TRUNC", "quarter", "2020-06-30"), result: "2020-04-01"},
+		{name: "truncYear", call: call("DATE_TRUNC", "year", "2020-04-23"), result: "2020-01-01"},
+		{name: "truncUnknown", call: call("DATE_TRUNC", "decade", "2020-04-23"), result: ""},
+		{name: "datediff", call: call("DATEDIFF", "2020-04-30", "2020-04-20"), result: "10"},
+		{name: "hour", call: call("HOUR", "2020-04-20T10:30:00+03:00"), result: "10"},
+		{name: "minute", call: call("MINUTE", "2020-04-20 10:30:00"), result: "30"},
+		{name: "truncTimestamp", call: call("DATE_TRUNC", "month", "2020-04-20T10:30:00Z"), result: "2020-04-01"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, Data(tc.result), tc.call.Eval(RowData{}))
+		})
+	}
+}
+
+func TestDateDiffTimeZone(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+	f := newFormats(&Options{DateLayouts: []string{"02.01.2006"}, Location: location})
+
+	tests := []struct {
+		name   string
+		date   string
+		other  string
+		result string
+	}{
+		{name: "summerTime", date: "2020-03-30", other: "2020-03-29", result: "1"},
+		{name: "winterTime", date: "2020-10-26", other: "2020-10-25", result: "1"},
+		{name: "summer", date: "2020-10-25", other: "2020-03-29", result: "210"},
+		{name: "layout", date: "30.03.2020", other: "2020-01-01", result: "89"},
+		{name: "backwards", date: "2020-01-01", other: "30.03.2020", result: "-89"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			call := &Function{Name: "DATEDIFF", Args: []Expression{
+				&Literal{Value: f.parse(tc.date)},
+				&Literal{Value: f.parse(tc.other)},
+			}}
+			assert.Equal(t, tc.result, call.Eval(RowData{}).String())
+		})
+	}
+}
+
+func TestRequestDoDates(t *testing.T) {
+	now = func() time.Time {
+		return time.Date(2020, 4, 30, 15, 0, 0, 0, time.UTC)
+	}
+	defer func() { now = time.Now }()
+
+	req, err := NewRequest(`SELECT DATE_TRUNC('week', date) AS week, SUM(new_cases) AS cases
+	FROM ./test/owid-covid-data.csv
+	WHERE location = Russia AND date > CURRENT_DATE - INTERVAL '7 days'
+	GROUP BY week
+	ORDER BY week;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	var rows []string
+	for _, row := range result.Data {
+		rows = append(rows, row["week"]+" "+row["cases"])
+	}
+	assert.Equal(t, []string{"2020-04-20 18176.0", "2020-04-27 25549.0"}, rows)
+}
+
+func TestRequestDoLayoutDates(t *testing.T) {
+	tests := []struct {
+		name    string
+		where   string
+		layouts []string
+		expect  []string
+	}{
+		{name: "slashes", where: "date = 04/26/2020", layouts: []string{"01/02/2006"}, expect: []string{"2020-04-26"}},
+		{name: "points", where: "date = 26.04.2020", layouts: []string{"02.01.2006"}, expect: []string{"2020-04-26"}},
+		{
+			name:    "between",
+			where:   "date BETWEEN 25.04.2020 AND 26.04.2020",
+			layouts: []string{"02.01.2006"},
+			expect:  []string{"2020-04-25", "2020-04-26"},
+		},
+		{name: "quoted", where: "date = '26.04.2020'", layouts: []string{"02.01.2006"}, expect: []string{"2020-04-26"}},
+		{name: "arithmetic", where: "date = 25.04.2020 + 1", layouts: []string{"02.01.2006"}, expect: []string{"2020-04-26"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest("SELECT date FROM ./test/owid-covid-data.csv WHERE location = Russia AND "+tc.where,
+				&Options{DateLayouts: tc.layouts})
+			assert.NoError(t, err)
+			result, err := req.Do(context.Background(), ",")
+			assert.NoError(t, err)
+
+			dates := make([]string, len(result.Data))
+			for ind, data := range result.Data {
+				dates[ind] = data["date"]
+			}
+			assert.Equal(t, tc.expect, dates)
+		})
+	}
+}
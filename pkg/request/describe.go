@@ -0,0 +1,185 @@
+package request
+
+import (
+	"bufio"
+	"context"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// describeSample is the number of rows of the file which are read by DESCRIBE request.
+const describeSample = 10000
+
+// describeNames are the names of the fields in the results of DESCRIBE request.
+var describeNames = []string{"field", "type", "null_ratio", "invalid", "distinct", "min", "max"}
+
+// selectNames returns the items which select the fields with the given names.
+func selectNames(names []string) []*SelectItem {
+	items := make([]*SelectItem, len(names))
+	for ind, name := range names {
+		items[ind] = &SelectItem{Expr: &Column{Name: name}}
+	}
+	return items
+}
+
+// describe samples the first rows of the file and reports each field with its type,
+// the ratio of NULL values, the number of values which do not match the declared type,
+// the estimated number of unique values and the bounds.
+// The type is inferred from the values unless it is declared in the schema.
+func (r *Request) describe(ctx context.Context, csvSep string) (*Results, error) {
+	headers, err := getHeaders(r.From)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Results{Request: r, MaxLength: make(IndexMap)}
+	for _, name := range describeNames {
+		result.MaxLength[name] = len(name)
+	}
+	lines, err := result.sampleLines(ctx, csvSep)
+	if err != nil {
+		return nil, err
+	}
+
+	for ind, field := range headers {
+		values := make([]string, len(lines))
+		for lineInd, line := range lines {
+			if ind < len(line) {
+				values[lineInd] = line[ind]
+			}
+		}
+		data := describeField(field, values, r.Schema[field], r.formats)
+		for name, value := range data {
+			if length := result.MaxLength[name]; length < len(value) {
+				result.MaxLength[name] = len(value)
+			}
+		}
+		result.Data = append(result.Data, data)
+	}
+	result.HasData = true
+	return result, nil
+}
+
+// sampleLines reads up to describeSample rows of the file after the headers.
+// Values are not converted to the declared types, so the invalid ones are counted
+// by describeField even if strict_types is set.
+func (r *Results) sampleLines(ctx context.Context, csvSep string) ([][]string, error) {
+	f, err := os.Open(r.Request.From)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Split(bufio.ScanLines)
+
+	var lines [][]string
+	var lineNumber int
+	for scanner.Scan() && len(lines) < describeSample {
+		lineNumber++
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if lineNumber == 1 {
+			continue
+		}
+
+		line := strings.Split(scanner.Text(), csvSep)
+		clearNullMarkers(line, r.Request.getOptions().NullMarkers)
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// describeField returns the row of DESCRIBE results for the values of the field.
+// Bounds are compared by the type of the field, so 10 is greater than 9 in INT field
+// and less than 9 in STRING field.
+func describeField(field string, values []string, dataType string, f *formats) RowData {
+	if dataType == "" {
+		dataType = inferType(values, f)
+	}
+
+	stats := &fieldStats{distinct: newDistinctCounter()}
+	for _, value := range values {
+		stats.add(f.parse(value), dataType)
+	}
+
+	data := RowData{
+		"field":    field,
+		"type":     dataType,
+		"invalid":  strconv.Itoa(stats.invalid),
+		"distinct": strconv.Itoa(stats.distinct.count()),
+	}
+	if len(values) > 0 {
+		ratio := float64(stats.nulls) / float64(len(values))
+		data["null_ratio"] = formatFloat(math.Round(ratio*1000) / 1000)
+	}
+	if stats.lowest != nil {
+		data["min"], data["max"] = stats.lowest.String(), stats.highest.String()
+	}
+	return data
+}
+
+// fieldStats accumulates the values of the field converted to its type.
+type fieldStats struct {
+	distinct *distinctCounter
+	lowest   Variable
+	highest  Variable
+	nulls    int
+	invalid  int
+}
+
+func (s *fieldStats) add(value Variable, dataType string) {
+	typed, ok := castValue(value, dataType)
+	switch {
+	case !ok:
+		s.invalid++
+		return
+	case isNull(typed):
+		s.nulls++
+		return
+	}
+	s.distinct.add(typed.String())
+	if s.lowest == nil || compareValues(typed, s.lowest) < 0 {
+		s.lowest = typed
+	}
+	if s.highest == nil || compareValues(typed, s.highest) > 0 {
+		s.highest = typed
+	}
+}
+
+// inferType returns the narrowest type of all not NULL values, e.g. FLOAT
+// for integers and floats. It is STRING if the values have no common type
+// or all of them are NULL.
+func inferType(values []string, f *formats) string {
+	var dataType string
+	for _, value := range values {
+		data := f.parse(value)
+		if isNull(data) {
+			continue
+		}
+		if dataType = commonType(dataType, data.defineType()); dataType == typeString {
+			return typeString
+		}
+	}
+	if dataType == "" {
+		return typeString
+	}
+	return dataType
+}
+
+// commonType returns the narrowest type of the values of both types.
+// The first type is empty if there were no values before.
+func commonType(dataType, valueType string) string {
+	switch {
+	case dataType == "" || dataType == valueType:
+		return valueType
+	case isNumber(dataType) && isNumber(valueType):
+		return typeFloat
+	case isTime(dataType) && isTime(valueType):
+		return typeTimestamp
+	}
+	return typeString
+}
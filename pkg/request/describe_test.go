@@ -0,0 +1,96 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		result string
+	}{
+		{name: "integers", values: []string{"1", "", "-5"}, result: typeInteger},
+		{name: "numbers", values: []string{"1", "2.5"}, result: typeFloat},
+		{name: "dates", values: []string{"2020-11-18", "2020-11-19"}, result: typeDate},
+		{name: "timestamps", values: []string{"2020-11-18", "2020-11-19 10:30:00"}, result: typeTimestamp},
+		{name: "bools", values: []string{"true", "False"}, result: typeBool},
+		{name: "mixed", values: []string{"1", "N/A", "2.5"}, result: typeString},
+		{name: "nulls", values: []string{"", ""}, result: typeString},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.result, inferType(tc.values, nil))
+		})
+	}
+}
+
+func TestDescribeField(t *testing.T) {
+	assert.Equal(t,
+		RowData{
+			"field": "cases", "type": typeFloat, "null_ratio": "0.25", "invalid": "0",
+			"distinct": "2", "min": "9.0", "max": "10.5",
+		},
+		describeField("cases", []string{"10.5", "", "9", "9.0"}, "", nil),
+	)
+	assert.Equal(t,
+		RowData{
+			"field": "code", "type": typeString, "null_ratio": "0.0", "invalid": "0",
+			"distinct": "2", "min": "10", "max": "9",
+		},
+		describeField("code", []string{"9", "10"}, typeString, nil),
+	)
+	assert.Equal(t,
+		RowData{"field": "empty", "type": typeString, "invalid": "0", "distinct": "0"},
+		describeField("empty", nil, "", nil),
+	)
+	assert.Equal(t,
+		RowData{
+			"field": "cases", "type": typeInteger, "null_ratio": "0.333", "invalid": "1",
+			"distinct": "1", "min": "12", "max": "12",
+		},
+		describeField("cases", []string{"N/A", "", "12.0"}, typeInteger, nil),
+	)
+}
+
+func TestRequestDoDescribe(t *testing.T) {
+	req, err := NewRequest("DESCRIBE ./test/typed.csv;", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, describeNames, getSelectNames(req.Select))
+
+	result, err := req.Do(context.Background(), ",")
+	assert.NoError(t, err)
+	assert.Equal(t, []RowData{
+		{
+			"field": "id", "type": typeInteger, "null_ratio": "0.0", "invalid": "0",
+			"distinct": "3", "min": "1", "max": "3",
+		},
+		{
+			"field": "code", "type": typeString, "null_ratio": "0.0", "invalid": "0",
+			"distinct": "3", "min": "007", "max": "7",
+		},
+		{
+			"field": "cases", "type": typeInteger, "null_ratio": "0.0", "invalid": "1",
+			"distinct": "2", "min": "10", "max": "12",
+		},
+		{
+			"field": "reported", "type": typeDate, "null_ratio": "0.0", "invalid": "0",
+			"distinct": "3", "min": "2020-11-18", "max": "2020-11-20",
+		},
+	}, result.Data)
+	assert.Equal(t, 10, result.MaxLength["min"])
+
+	// Invalid values are counted instead of failing the request.
+	req, err = NewRequest("DESCRIBE ./test/typed.csv;", &Options{StrictTypes: true})
+	assert.NoError(t, err)
+	result, err = req.Do(context.Background(), ",")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", result.Data[2]["invalid"])
+
+	_, err = NewRequest("DESCRIBE ./test/missing.csv", nil)
+	assert.EqualError(t, err, "open ./test/missing.csv: no such file or directory")
+}
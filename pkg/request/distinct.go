@@ -0,0 +1,111 @@
+package request
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+const (
+	// distinctExactLimit is the number of unique values which are counted exactly.
+	distinctExactLimit = 1 << 16
+	// hllPrecision defines the number of HyperLogLog registers: 2^14 registers
+	// take 16KB and give about 0.8% standard error.
+	hllPrecision = 14
+)
+
+// hashSet keeps hashes of the values instead of the values themselves,
+// so it takes 8 bytes per unique value regardless of the length of the value.
+type hashSet map[uint64]struct{}
+
+// add adds the hash to the set and returns false if it was already there.
+func (s hashSet) add(hash uint64) bool {
+	if _, ok := s[hash]; ok {
+		return false
+	}
+	s[hash] = struct{}{}
+	return true
+}
+
+// hashValues returns 64-bit hash of the values list.
+func hashValues(values ...string) uint64 {
+	h := fnv.New64a()
+	for _, value := range values {
+		h.Write([]byte(value))
+		h.Write([]byte{0})
+	}
+	return mix(h.Sum64())
+}
+
+// mix spreads the bits of FNV hash, since HyperLogLog relies on the high bits
+// which are poorly distributed by FNV for short strings.
+func mix(hash uint64) uint64 {
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
+
+// distinctCounter counts unique values. It counts them exactly until
+// distinctExactLimit is reached and then switches to HyperLogLog estimation,
+// so the memory used by the counter is bounded for high-cardinality columns.
+type distinctCounter struct {
+	exact     hashSet
+	registers []uint8
+}
+
+func newDistinctCounter() *distinctCounter {
+	return &distinctCounter{exact: make(hashSet)}
+}
+
+func (c *distinctCounter) add(value string) {
+	hash := hashValues(value)
+	if c.registers != nil {
+		c.addToRegisters(hash)
+		return
+	}
+
+	c.exact.add(hash)
+	if len(c.exact) > distinctExactLimit {
+		c.registers = make([]uint8, 1<<hllPrecision)
+		for hash := range c.exact {
+			c.addToRegisters(hash)
+		}
+		c.exact = nil
+	}
+}
+
+func (c *distinctCounter) addToRegisters(hash uint64) {
+	ind := hash >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > c.registers[ind] {
+		c.registers[ind] = rank
+	}
+}
+
+// count returns the exact number of unique values or its estimation.
+func (c *distinctCounter) count() int {
+	if c.registers == nil {
+		return len(c.exact)
+	}
+
+	registers := float64(len(c.registers))
+	var sum float64
+	var zeros int
+	for _, rank := range c.registers {
+		sum += math.Pow(2, -float64(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/registers)
+	estimate := alpha * registers * registers / sum
+	// Linear counting gives better results for small cardinalities.
+	if estimate <= 2.5*registers && zeros > 0 {
+		estimate = registers * math.Log(registers/float64(zeros))
+	}
+	return int(math.Round(estimate))
+}
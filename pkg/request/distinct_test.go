@@ -0,0 +1,85 @@
+package request
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashSetAdd(t *testing.T) {
+	set := make(hashSet)
+	assert.True(t, set.add(hashValues("Russia", "2020-04-20")))
+	assert.True(t, set.add(hashValues("Russia2020-04-20")))
+	assert.False(t, set.add(hashValues("Russia", "2020-04-20")))
+}
+
+func TestDistinctCounterExact(t *testing.T) {
+	counter := newDistinctCounter()
+	for _, value := range []string{"RUS", "UKR", "RUS", "BLR", "UKR"} {
+		counter.add(value)
+	}
+	assert.Equal(t, 3, counter.count())
+	assert.Nil(t, counter.registers)
+}
+
+func TestDistinctCounterEstimate(t *testing.T) {
+	unique := 3 * distinctExactLimit
+	counter := newDistinctCounter()
+	for i := 0; i < unique; i++ {
+		counter.add(strconv.Itoa(i))
+		counter.add(strconv.Itoa(i))
+	}
+
+	assert.Nil(t, counter.exact)
+	assert.Len(t, counter.registers, 1<<hllPrecision)
+	assert.InEpsilon(t, unique, counter.count(), 0.03)
+}
+
+func TestRequestDoDistinct(t *testing.T) {
+	tests := []struct {
+		name      string
+		reqString string
+		expect    []RowData
+	}{
+		{
+			name:      "rows",
+			reqString: "SELECT DISTINCT location, continent FROM ./test/owid-covid-data.csv;",
+			expect: []RowData{
+				{"location": "Russia", "continent": "Europe"},
+				{"location": "Ukraine", "continent": "Europe"},
+			},
+		},
+		{
+			name:      "orderedWithLimit",
+			reqString: "SELECT DISTINCT location FROM ./test/owid-covid-data.csv ORDER BY location DESC LIMIT 1;",
+			expect:    []RowData{{"location": "Ukraine"}},
+		},
+		{
+			name:      "countDistinct",
+			reqString: "SELECT COUNT(DISTINCT location), COUNT(DISTINCT date), COUNT(*) FROM ./test/owid-covid-data.csv;",
+			expect: []RowData{
+				{"COUNT(DISTINCT location)": "2", "COUNT(DISTINCT date)": "11", "COUNT(*)": "22"},
+			},
+		},
+		{
+			name:      "distinctGroups",
+			reqString: "SELECT DISTINCT COUNT(*) FROM ./test/owid-covid-data.csv GROUP BY location;",
+			expect:    []RowData{{"COUNT(*)": "11", "location": "Russia"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest(tc.reqString, nil)
+			if err != nil {
+				t.Errorf("error: %s", err)
+			}
+
+			result, err := req.Do(context.Background(), ",")
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, result.Data)
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package request
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	plus     string = "+"
+	minus    string = "-"
+	multiply string = "*"
+	divide   string = "/"
+	modulo   string = "%"
+)
+
+var arithmeticOperators = []string{plus, minus, multiply, divide, modulo}
+
+// allFields is a special name of the column which selects all fields of the file.
+const allFields = "*"
+
+// Expression is a node of the value expression tree.
+// It computes the value from the row of the file or from the row of the groups.
+type Expression interface {
+	Eval(row Record) Variable
+	GetFields() []string
+	String() string
+}
+
+// Column is a reference to the field of the csv file.
+// Type is set if the field is declared in the schema of the file, see Schema.
+type Column struct {
+	Name    string
+	Type    string
+	formats *formats
+}
+
+// Eval returns the value of the field in the row.
+func (c *Column) Eval(row Record) Variable {
+	return fieldValue(row.Get(c.Name), c.Type, c.formats)
+}
+
+// GetFields returns the name of the field.
+func (c *Column) GetFields() []string {
+	return []string{c.Name}
+}
+
+// String returns the name of the field.
+func (c *Column) String() string {
+	return c.Name
+}
+
+// fieldValue returns the value of the field of the declared type, if any.
+func fieldValue(value, dataType string, f *formats) Variable {
+	if dataType == "" {
+		return f.parse(value)
+	}
+	typed, _ := castValue(f.parse(value), dataType)
+	return typed
+}
+
+// declaredType returns the type of the values of the expression if it does not
+// depend on their content, e.g. the type of the declared field or of CAST.
+func declaredType(expr Expression) string {
+	switch e := expr.(type) {
+	case *Column:
+		return e.Type
+	case *Word:
+		return e.Type
+	case *Cast:
+		return e.Type
+	}
+	return ""
+}
+
+// Literal is a constant value of the request.
+type Literal struct {
+	Value Variable
+}
+
+// Eval returns the value of the Literal.
+func (l *Literal) Eval(row Record) Variable {
+	return l.Value
+}
+
+// GetFields returns nothing, since the Literal does not depend on the row.
+func (l *Literal) GetFields() []string {
+	return nil
+}
+
+// String returns the value of the Literal. Strings are wrapped in quotes,
+// so they can be distinguished from the fields in the names of the expressions.
+func (l *Literal) String() string {
+	if l.Value.isFloat() || l.Value.isDate() || l.Value.isBool() {
+		return l.Value.String()
+	}
+	return "'" + strings.ReplaceAll(l.Value.String(), "'", "''") + "'"
+}
+
+// Word is a bare word on the value side of the condition. It refers to the field
+// if the file has such header and it is the value itself otherwise, e.g. new_cases
+// is the field in "new_deaths > new_cases" and Russia is the value in "location = Russia".
+// Field and Type are set when the request is checked against the headers of the file.
+type Word struct {
+	Text    string
+	Field   bool
+	Type    string
+	formats *formats
+}
+
+// Eval returns the value of the field in the row or the word in lower case.
+func (w *Word) Eval(row Record) Variable {
+	if w.Field {
+		return fieldValue(row.Get(w.Text), w.Type, w.formats)
+	}
+	return w.formats.parse(strings.ToLower(w.Text))
+}
+
+// GetFields returns the word if it refers to the field.
+func (w *Word) GetFields() []string {
+	if w.Field {
+		return []string{w.Text}
+	}
+	return nil
+}
+
+// String returns the word as it is written in the request.
+func (w *Word) String() string {
+	return w.Text
+}
+
+// Arithmetic applies +, -, *, / or % operator to the values of both sides.
+// The result is empty (NULL) if any side is not a number or the divisor is zero.
+// Division always returns a float, other operators keep integers as integers.
+// Dates can be shifted by intervals and days or subtracted, see dateArithmetic.
+type Arithmetic struct {
+	Left     Expression
+	Right    Expression
+	Operator string
+}
+
+// Eval computes the result of the operator for the row.
+func (a *Arithmetic) Eval(row Record) Variable {
+	left, right := a.Left.Eval(row), a.Right.Eval(row)
+	if result, ok := dateArithmetic(a.Left, a.Right, left, right, a.Operator); ok {
+		return result
+	}
+	compute, ok := operations[a.Operator]
+	if !ok || !isNumber(left.defineType()) || !isNumber(right.defineType()) {
+		return Data("")
+	}
+	return compute(left, right)
+}
+
+// operations compute the arithmetic operators for the numbers. The result
+// of the integers is the integer, except for the division.
+var operations = map[string]func(left, right Variable) Variable{
+	plus:     sum,
+	minus:    difference,
+	multiply: product,
+	divide:   quotient,
+	modulo:   remainder,
+}
+
+// integers defines if both numbers are integers.
+func integers(left, right Variable) bool {
+	return left.defineType() == typeInteger && right.defineType() == typeInteger
+}
+
+func sum(left, right Variable) Variable {
+	if integers(left, right) {
+		return Data(strconv.Itoa(left.toInteger() + right.toInteger()))
+	}
+	return Data(formatFloat(left.toFloat() + right.toFloat()))
+}
+
+func difference(left, right Variable) Variable {
+	if integers(left, right) {
+		return Data(strconv.Itoa(left.toInteger() - right.toInteger()))
+	}
+	return Data(formatFloat(left.toFloat() - right.toFloat()))
+}
+
+func product(left, right Variable) Variable {
+	if integers(left, right) {
+		return Data(strconv.Itoa(left.toInteger() * right.toInteger()))
+	}
+	return Data(formatFloat(left.toFloat() * right.toFloat()))
+}
+
+// quotient is NULL if the divisor is zero.
+func quotient(left, right Variable) Variable {
+	if right.toFloat() == 0 {
+		return Data("")
+	}
+	return Data(formatFloat(left.toFloat() / right.toFloat()))
+}
+
+// remainder is NULL if the divisor is zero.
+func remainder(left, right Variable) Variable {
+	switch {
+	case right.toFloat() == 0:
+		return Data("")
+	case integers(left, right):
+		return Data(strconv.Itoa(left.toInteger() % right.toInteger()))
+	}
+	return Data(formatFloat(math.Mod(left.toFloat(), right.toFloat())))
+}
+
+// GetFields returns unique fields used in both sides of the Arithmetic.
+func (a *Arithmetic) GetFields() []string {
+	fields := a.Left.GetFields()
+	for _, field := range a.Right.GetFields() {
+		if !sliceHasString(field, fields) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// String returns the expression as it is written in the request.
+// Brackets are kept only where they change the order of operations.
+func (a *Arithmetic) String() string {
+	left, right := a.Left.String(), a.Right.String()
+	if l, ok := a.Left.(*Arithmetic); ok && l.precedence() < a.precedence() {
+		left = "(" + left + ")"
+	}
+	if r, ok := a.Right.(*Arithmetic); ok && r.precedence() <= a.precedence() {
+		right = "(" + right + ")"
+	}
+	return fmt.Sprintf("%s %s %s", left, a.Operator, right)
+}
+
+func (a *Arithmetic) precedence() int {
+	if a.Operator == plus || a.Operator == minus {
+		return 1
+	}
+	return 2
+}
+
+// Negative changes the sign of the number, e.g. -new_cases.
+type Negative struct {
+	Expr Expression
+}
+
+// Eval returns the number with the opposite sign or empty (NULL) value if it is not a number.
+func (n *Negative) Eval(row Record) Variable {
+	return (&Arithmetic{Left: &Literal{Value: Data("0")}, Right: n.Expr, Operator: minus}).Eval(row)
+}
+
+// GetFields returns fields used in the expression.
+func (n *Negative) GetFields() []string {
+	return n.Expr.GetFields()
+}
+
+// String returns the expression as it is written in the request.
+func (n *Negative) String() string {
+	str := n.Expr.String()
+	if _, ok := n.Expr.(*Arithmetic); ok || strings.HasPrefix(str, minus) {
+		return fmt.Sprintf("%s(%s)", minus, str)
+	}
+	return minus + str
+}
+
+// SelectItem is a single element of SELECT statement.
+// Alias replaces the expression name in the printed results.
+type SelectItem struct {
+	Expr  Expression
+	Alias string
+}
+
+// Name returns the name of the item in the results.
+func (s *SelectItem) Name() string {
+	if s.Alias != "" {
+		return s.Alias
+	}
+	return s.Expr.String()
+}
+
+func getSelectNames(items []*SelectItem) []string {
+	names := make([]string, len(items))
+	for ind, item := range items {
+		names[ind] = item.Name()
+	}
+	return names
+}
+
+func getSelectFields(items []*SelectItem) []string {
+	var fields []string
+	for _, item := range items {
+		for _, field := range item.Expr.GetFields() {
+			if !sliceHasString(field, fields) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,122 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestDoAliases(t *testing.T) {
+	req, err := NewRequest(`SELECT location AS country, new_cases AS 'new cases'
+	FROM ./test/owid-covid-data.csv
+	WHERE date = 2020-04-30
+	ORDER BY country DESC;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, IndexMap{"country": 7, "new cases": 9}, result.MaxLength)
+
+	var countries []string
+	for _, row := range result.Data {
+		countries = append(countries, row["country"])
+	}
+	assert.Equal(t, []string{"Ukraine", "Russia"}, countries)
+
+	req, err = NewRequest(`SELECT location AS country, SUM(new_cases) AS total
+	FROM ./test/owid-covid-data.csv
+	GROUP BY location
+	HAVING total > 10000;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err = req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, "Russia", result.Data[0]["country"])
+	assert.Equal(t, "63645.0", result.Data[0]["total"])
+}
+
+func TestArithmeticEval(t *testing.T) {
+	row := RowData{"int": "7", "float": "2.5", "zero": "0", "empty": "", "text": "abc"}
+	tests := []struct {
+		name     string
+		left     string
+		right    string
+		operator string
+		result   string
+	}{
+		{name: "intSum", left: "int", right: "int", operator: plus, result: "14"},
+		{name: "intModulo", left: "int", right: "int", operator: modulo, result: "0"},
+		{name: "floatSum", left: "int", right: "float", operator: plus, result: "9.5"},
+		{name: "floatProduct", left: "float", right: "float", operator: multiply, result: "6.25"},
+		{name: "intDivision", left: "int", right: "int", operator: divide, result: "1.0"},
+		{name: "divisionByZero", left: "int", right: "zero", operator: divide, result: ""},
+		{name: "moduloByZero", left: "int", right: "zero", operator: modulo, result: ""},
+		{name: "emptyCell", left: "empty", right: "int", operator: minus, result: ""},
+		{name: "string", left: "text", right: "int", operator: plus, result: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expr := &Arithmetic{Left: &Column{Name: tc.left}, Right: &Column{Name: tc.right}, Operator: tc.operator}
+			assert.Equal(t, tc.result, expr.Eval(row).String())
+		})
+	}
+}
+
+func TestArithmeticString(t *testing.T) {
+	sum := &Arithmetic{Left: &Column{Name: "a"}, Right: &Column{Name: "b"}, Operator: plus}
+	expr := &Arithmetic{
+		Left:     &Arithmetic{Left: sum, Right: &Literal{Value: Data("2")}, Operator: multiply},
+		Right:    &Arithmetic{Left: &Column{Name: "c"}, Right: &Column{Name: "d"}, Operator: minus},
+		Operator: minus,
+	}
+	assert.Equal(t, "(a + b) * 2 - (c - d)", expr.String())
+}
+
+func TestUnaryMinus(t *testing.T) {
+	r, _, err := parseRequest("SELECT - -5, -2.5, -'abc', -a, -(a + b), - -a FROM ./test/owid-covid-data.csv", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5", "-2.5", "-'abc'", "-a", "-(a + b)", "-(-a)"}, getSelectNames(r.Select))
+
+	row := RowData{"a": "4", "b": "1.5"}
+	values := make([]string, len(r.Select))
+	for ind, item := range r.Select {
+		values[ind] = item.Expr.Eval(row).String()
+	}
+	assert.Equal(t, []string{"5", "-2.5", "", "-4", "-5.5", "4"}, values)
+}
+
+func TestRequestDoArithmetic(t *testing.T) {
+	req, err := NewRequest(`SELECT date, new_deaths * 100 / new_cases AS cfr, total_cases - new_cases
+	FROM ./test/owid-covid-data.csv
+	WHERE location = Ukraine AND total_deaths * 1000 >= 200000;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 7)
+	assert.Equal(t, "2020-04-24", result.Data[0]["date"])
+	assert.Equal(t, "2.9350104821802936", result.Data[0]["cfr"])
+	assert.Equal(t, "7170.0", result.Data[0]["total_cases - new_cases"])
+
+	req, err = NewRequest(`SELECT location, SUM(new_deaths) * 100 / SUM(new_cases) AS cfr
+	FROM ./test/owid-covid-data.csv
+	GROUP BY location
+	HAVING SUM(new_deaths) / COUNT(*) > 50;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err = req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, "Russia", result.Data[0]["location"])
+}
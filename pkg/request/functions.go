@@ -0,0 +1,225 @@
+package request
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// scalarFunction is a built-in function which computes the value of a single row.
+// If nulls is not set, the function returns NULL when any of the arguments is NULL.
+type scalarFunction struct {
+	call    func(args []Variable) Variable
+	minArgs int
+	// maxArgs is -1 if the number of arguments is not limited.
+	maxArgs int
+	nulls   bool
+}
+
+// scalarFunctions is a registry of the functions which can be used in expressions.
+var scalarFunctions = map[string]*scalarFunction{
+	"UPPER": {call: stringFunction(strings.ToUpper), minArgs: 1, maxArgs: 1},
+	"LOWER": {call: stringFunction(strings.ToLower), minArgs: 1, maxArgs: 1},
+	"TRIM":  {call: stringFunction(strings.TrimSpace), minArgs: 1, maxArgs: 1},
+	"LENGTH": {call: func(args []Variable) Variable {
+		return Data(fmt.Sprint(utf8.RuneCountInString(args[0].String())))
+	}, minArgs: 1, maxArgs: 1},
+	"SUBSTR": {call: substr, minArgs: 2, maxArgs: 3},
+	"REPLACE": {call: func(args []Variable) Variable {
+		return Data(strings.ReplaceAll(args[0].String(), args[1].String(), args[2].String()))
+	}, minArgs: 3, maxArgs: 3},
+	"CONCAT":     {call: concat, minArgs: 1, maxArgs: -1, nulls: true},
+	"COALESCE":   {call: coalesce, minArgs: 1, maxArgs: -1, nulls: true},
+	"NULLIF":     {call: nullif, minArgs: 2, maxArgs: 2, nulls: true},
+	"YEAR":       {call: datePart(time.Time.Year), minArgs: 1, maxArgs: 1},
+	"MONTH":      {call: datePart(func(t time.Time) int { return int(t.Month()) }), minArgs: 1, maxArgs: 1},
+	"DAY":        {call: datePart(time.Time.Day), minArgs: 1, maxArgs: 1},
+	"HOUR":       {call: datePart(time.Time.Hour), minArgs: 1, maxArgs: 1},
+	"MINUTE":     {call: datePart(time.Time.Minute), minArgs: 1, maxArgs: 1},
+	"DATE_TRUNC": {call: dateTrunc, minArgs: 2, maxArgs: 2},
+	"DATEDIFF":   {call: dateDiff, minArgs: 2, maxArgs: 2},
+	"ROUND":      {call: round, minArgs: 1, maxArgs: 2},
+	"FLOOR":      {call: numberFunction(math.Floor), minArgs: 1, maxArgs: 1},
+	"CEIL":       {call: numberFunction(math.Ceil), minArgs: 1, maxArgs: 1},
+	"ABS":        {call: abs, minArgs: 1, maxArgs: 1},
+	"SQRT": {call: func(args []Variable) Variable {
+		if !args[0].isFloat() || args[0].toFloat() < 0 {
+			return Data("")
+		}
+		return Data(formatFloat(math.Sqrt(args[0].toFloat())))
+	}, minArgs: 1, maxArgs: 1},
+	"POWER": {call: func(args []Variable) Variable {
+		if !args[0].isFloat() || !args[1].isFloat() {
+			return Data("")
+		}
+		result := math.Pow(args[0].toFloat(), args[1].toFloat())
+		if math.IsNaN(result) || math.IsInf(result, 0) {
+			return Data("")
+		}
+		return Data(formatFloat(result))
+	}, minArgs: 2, maxArgs: 2},
+}
+
+func stringFunction(f func(string) string) func(args []Variable) Variable {
+	return func(args []Variable) Variable {
+		return Data(f(args[0].String()))
+	}
+}
+
+// numberFunction rounds the number to the whole one, integers are returned as they are.
+// The result is NULL if the value is not a number.
+func numberFunction(f func(float64) float64) func(args []Variable) Variable {
+	return func(args []Variable) Variable {
+		switch {
+		case args[0].isInteger():
+			return args[0]
+		case args[0].isFloat():
+			// Adding zero turns the negative zero, e.g. CEIL(-0.5), into 0.
+			return Data(strconv.FormatFloat(f(args[0].toFloat())+0, 'f', 0, 64))
+		}
+		return Data("")
+	}
+}
+
+// round rounds the number half away from zero to the given number of digits
+// after the point, which can be negative. By default it rounds to the whole number.
+func round(args []Variable) Variable {
+	digits := 0
+	if len(args) == 2 {
+		if !args[1].isInteger() {
+			return Data("")
+		}
+		digits = args[1].toInteger()
+	}
+	if !args[0].isFloat() {
+		return Data("")
+	}
+	if args[0].isInteger() && digits >= 0 {
+		return args[0]
+	}
+
+	scale := math.Pow(10, float64(digits))
+	result := math.Round(args[0].toFloat()*scale)/scale + 0
+	if digits <= 0 {
+		return Data(strconv.FormatFloat(result, 'f', 0, 64))
+	}
+	return Data(formatFloat(result))
+}
+
+// abs returns the absolute value of the number keeping its type.
+func abs(args []Variable) Variable {
+	switch {
+	case args[0].isInteger():
+		if num := args[0].toInteger(); num < 0 {
+			return Data(strconv.Itoa(-num))
+		}
+		return args[0]
+	case args[0].isFloat():
+		return Data(formatFloat(math.Abs(args[0].toFloat())))
+	}
+	return Data("")
+}
+
+// substr returns the part of the string from the start position, which counts from 1,
+// with the given length or until the end of the string.
+func substr(args []Variable) Variable {
+	str := []rune(args[0].String())
+	if !args[1].isInteger() {
+		return Data("")
+	}
+	start, end := args[1].toInteger(), len(str)+1
+	if len(args) == 3 {
+		if !args[2].isInteger() || args[2].toInteger() < 0 {
+			return Data("")
+		}
+		end = start + args[2].toInteger()
+	}
+
+	if start < 1 {
+		start = 1
+	}
+	if end > len(str)+1 {
+		end = len(str) + 1
+	}
+	if start >= end {
+		return Data("")
+	}
+	return Data(string(str[start-1 : end-1]))
+}
+
+// concat joins the strings, NULL arguments are skipped.
+func concat(args []Variable) Variable {
+	var b strings.Builder
+	for _, arg := range args {
+		if !isNull(arg) {
+			b.WriteString(arg.String())
+		}
+	}
+	return Data(b.String())
+}
+
+// coalesce returns the first argument which is not NULL.
+func coalesce(args []Variable) Variable {
+	for _, arg := range args {
+		if !isNull(arg) {
+			return arg
+		}
+	}
+	return Data("")
+}
+
+// nullif returns NULL if both arguments are equal and the first argument otherwise.
+// Numbers are compared numerically, so NULLIF(new_cases, 0) replaces 0.0 too.
+func nullif(args []Variable) Variable {
+	if isNull(args[0]) || isNull(args[1]) {
+		return args[0]
+	}
+	if canCompare(args[1], args[0]) && compareValues(args[0], args[1]) == 0 {
+		return Data("")
+	}
+	return args[0]
+}
+
+// Function is a call of the scalar function.
+type Function struct {
+	Name string
+	Args []Expression
+}
+
+// Eval computes the value of the function for the row.
+func (f *Function) Eval(row Record) Variable {
+	fn := scalarFunctions[f.Name]
+	args := make([]Variable, len(f.Args))
+	for ind, arg := range f.Args {
+		args[ind] = arg.Eval(row)
+		if !fn.nulls && isNull(args[ind]) {
+			return Data("")
+		}
+	}
+	return fn.call(args)
+}
+
+// GetFields returns unique fields used in the arguments.
+func (f *Function) GetFields() []string {
+	var fields []string
+	for _, arg := range f.Args {
+		for _, field := range arg.GetFields() {
+			if !sliceHasString(field, fields) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// String returns the call as it is written in the request.
+func (f *Function) String() string {
+	args := make([]string, len(f.Args))
+	for ind, arg := range f.Args {
+		args[ind] = arg.String()
+	}
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(args, ", "))
+}
@@ -0,0 +1,185 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctionEval(t *testing.T) {
+	row := RowData{"text": " Hello, World ", "name": "Україна", "empty": "", "num": "3"}
+	tests := []struct {
+		name   string
+		call   *Function
+		result string
+	}{
+		{
+			name:   "upper",
+			call:   &Function{Name: "UPPER", Args: []Expression{&Column{Name: "name"}}},
+			result: "УКРАЇНА",
+		},
+		{
+			name:   "lower",
+			call:   &Function{Name: "LOWER", Args: []Expression{&Column{Name: "text"}}},
+			result: " hello, world ",
+		},
+		{
+			name:   "trim",
+			call:   &Function{Name: "TRIM", Args: []Expression{&Column{Name: "text"}}},
+			result: "Hello, World",
+		},
+		{
+			name:   "lengthRunes",
+			call:   &Function{Name: "LENGTH", Args: []Expression{&Column{Name: "name"}}},
+			result: "7",
+		},
+		{
+			name: "substr",
+			call: &Function{Name: "SUBSTR", Args: []Expression{
+				&Column{Name: "name"}, &Column{Name: "num"}, &Literal{Value: Data("3")},
+			}},
+			result: "раї",
+		},
+		{
+			name:   "substrToEnd",
+			call:   &Function{Name: "SUBSTR", Args: []Expression{&Column{Name: "name"}, &Literal{Value: Data("5")}}},
+			result: "їна",
+		},
+		{
+			name:   "substrOutOfRange",
+			call:   &Function{Name: "SUBSTR", Args: []Expression{&Column{Name: "name"}, &Literal{Value: Data("10")}}},
+			result: "",
+		},
+		{
+			name: "replace",
+			call: &Function{Name: "REPLACE", Args: []Expression{
+				&Column{Name: "text"}, &Literal{Value: Data("o")}, &Literal{Value: Data("0")},
+			}},
+			result: " Hell0, W0rld ",
+		},
+		{
+			name:   "nullArgument",
+			call:   &Function{Name: "UPPER", Args: []Expression{&Column{Name: "empty"}}},
+			result: "",
+		},
+		{
+			name: "concatSkipsNull",
+			call: &Function{Name: "CONCAT", Args: []Expression{
+				&Column{Name: "num"}, &Column{Name: "empty"}, &Literal{Value: Data("-")}, &Column{Name: "num"},
+			}},
+			result: "3-3",
+		},
+		{
+			name:   "roundDigits",
+			call:   &Function{Name: "ROUND", Args: []Expression{&Literal{Value: Data("4113.286")}, &Literal{Value: Data("1")}}},
+			result: "4113.3",
+		},
+		{
+			name:   "roundWhole",
+			call:   &Function{Name: "ROUND", Args: []Expression{&Literal{Value: Data("-2.5")}}},
+			result: "-3",
+		},
+		{
+			name:   "roundNegativeDigits",
+			call:   &Function{Name: "ROUND", Args: []Expression{&Literal{Value: Data("4268")}, &Literal{Value: Data("-2")}}},
+			result: "4300",
+		},
+		{
+			name:   "roundInteger",
+			call:   &Function{Name: "ROUND", Args: []Expression{&Column{Name: "num"}, &Literal{Value: Data("2")}}},
+			result: "3",
+		},
+		{
+			name:   "roundString",
+			call:   &Function{Name: "ROUND", Args: []Expression{&Column{Name: "name"}}},
+			result: "",
+		},
+		{
+			name:   "floor",
+			call:   &Function{Name: "FLOOR", Args: []Expression{&Literal{Value: Data("-4.5")}}},
+			result: "-5",
+		},
+		{
+			name:   "ceil",
+			call:   &Function{Name: "CEIL", Args: []Expression{&Literal{Value: Data("4.1")}}},
+			result: "5",
+		},
+		{
+			name:   "ceilNegativeZero",
+			call:   &Function{Name: "CEIL", Args: []Expression{&Literal{Value: Data("-0.5")}}},
+			result: "0",
+		},
+		{
+			name:   "roundNegativeZero",
+			call:   &Function{Name: "ROUND", Args: []Expression{&Literal{Value: Data("-0.04")}, &Literal{Value: Data("1")}}},
+			result: "0.0",
+		},
+		{
+			name:   "absInteger",
+			call:   &Function{Name: "ABS", Args: []Expression{&Literal{Value: Data("-3")}}},
+			result: "3",
+		},
+		{
+			name:   "absFloat",
+			call:   &Function{Name: "ABS", Args: []Expression{&Literal{Value: Data("-4268.0")}}},
+			result: "4268.0",
+		},
+		{
+			name:   "sqrtNegative",
+			call:   &Function{Name: "SQRT", Args: []Expression{&Literal{Value: Data("-4")}}},
+			result: "",
+		},
+		{
+			name:   "power",
+			call:   &Function{Name: "POWER", Args: []Expression{&Column{Name: "num"}, &Literal{Value: Data("2")}}},
+			result: "9.0",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, Data(tc.result), tc.call.Eval(row))
+		})
+	}
+}
+
+func TestFunctionString(t *testing.T) {
+	call := &Function{Name: "CONCAT", Args: []Expression{
+		&Function{Name: "UPPER", Args: []Expression{&Column{Name: "location"}}},
+		&Literal{Value: Data(" - ")},
+		&Column{Name: "iso_code"},
+	}}
+	assert.Equal(t, "CONCAT(UPPER(location), ' - ', iso_code)", call.String())
+	assert.Equal(t, []string{"location", "iso_code"}, call.GetFields())
+}
+
+func TestRequestDoFunctions(t *testing.T) {
+	req, err := NewRequest(`SELECT CONCAT(UPPER(location), ' - ', iso_code) AS country, SUBSTR(date, 6) AS day
+	FROM ./test/owid-covid-data.csv
+	WHERE LENGTH(location) > 6 AND date = 2020-04-30;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 1)
+	assert.Equal(t, "UKRAINE - UKR", result.Data[0]["country"])
+	assert.Equal(t, "04-30", result.Data[0]["day"])
+
+	req, err = NewRequest(`SELECT date, ROUND(new_cases_smoothed, 1) AS smoothed
+	FROM ./test/owid-covid-data.csv
+	WHERE location = Russia AND ABS(new_cases - new_cases_smoothed) > 1000 OR date = 2020-04-20;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err = req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	var smoothed []string
+	for _, row := range result.Data {
+		smoothed = append(smoothed, row["date"]+" "+row["smoothed"])
+	}
+	assert.Equal(t, []string{"2020-04-20 4113.3", "2020-04-21 4523.0", "2020-04-20 372.6"}, smoothed)
+}
@@ -0,0 +1,203 @@
+package request
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokDate
+	tokString
+	tokSymbol
+)
+
+var datePrefix = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}`)
+
+// token is a single lexeme of the request string.
+// Pos is a byte offset of the token in the original string.
+type token struct {
+	Text string
+	Kind tokenKind
+	Pos  int
+}
+
+func (t token) String() string {
+	if t.Kind == tokEOF {
+		return "end of request"
+	}
+	return fmt.Sprintf("%q", t.Text)
+}
+
+// lexer splits the request string into tokens.
+// It never fails on unknown characters, they are returned as symbols
+// and it is up to the parser to decide if they are allowed.
+type lexer struct {
+	input []rune
+	pos   int
+	// offsets keeps byte offsets of the runes to report token positions.
+	offsets []int
+	// formats define the additional layouts of the dates which can be written without quotes.
+	formats *formats
+}
+
+func tokenize(str string, f *formats) ([]token, error) {
+	l := &lexer{formats: f}
+	for offset, r := range str {
+		l.input = append(l.input, r)
+		l.offsets = append(l.offsets, offset)
+	}
+	l.offsets = append(l.offsets, len(str))
+
+	var tokens []token
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.Kind == tokEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skip(unicode.IsSpace)
+	if l.pos >= len(l.input) {
+		return token{Kind: tokEOF, Pos: l.offsets[l.pos]}, nil
+	}
+
+	start := l.pos
+	r := l.input[l.pos]
+	switch {
+	case isIdentStart(r):
+		l.skip(isIdentPart)
+		return l.emit(tokIdent, start), nil
+	case unicode.IsDigit(r):
+		return l.number(start), nil
+	case r == '\'':
+		return l.quoted(start)
+	}
+
+	l.pos++
+	if l.pos < len(l.input) && isTwoCharSymbol(r, l.input[l.pos]) {
+		l.pos++
+	}
+	return l.emit(tokSymbol, start), nil
+}
+
+func (l *lexer) number(start int) token {
+	if end, ok := l.layoutDate(start); ok {
+		l.pos = end
+		return l.emit(tokDate, start)
+	}
+	if match := datePrefix.FindString(string(l.input[start:])); match != "" {
+		l.pos += len(match)
+		return l.emit(tokDate, start)
+	}
+
+	l.skip(unicode.IsDigit)
+	l.fraction()
+	// Numbers glued with letters, e.g. "45g", are kept as one bare word.
+	if l.pos < len(l.input) && isIdentStart(l.input[l.pos]) {
+		l.skip(isIdentPart)
+		return l.emit(tokIdent, start)
+	}
+	return l.emit(tokNumber, start)
+}
+
+// layoutDate returns the end of the date or of the timestamp written in one of the
+// additional layouts of the formats, e.g. 30.03.2020 or 03/30/2020 10:30. Such a date
+// is read as a whole, otherwise its parts would be the numbers and the symbols.
+func (l *lexer) layoutDate(start int) (int, bool) {
+	if l.formats == nil || l.formats == defaultFormats {
+		return 0, false
+	}
+	end := l.dateRun(start)
+	candidates := []int{end}
+	// The time of the timestamp is separated from the date by the space.
+	if end+1 < len(l.input) && l.input[end] == ' ' && unicode.IsDigit(l.input[end+1]) {
+		candidates = []int{l.dateRun(end + 1), end}
+	}
+	for _, candidate := range candidates {
+		if _, ok := l.formats.toDate(string(l.input[start:candidate])); ok {
+			return candidate, true
+		}
+	}
+	return 0, false
+}
+
+// dateRun returns the end of the digits and of the separators of the date starting at the position.
+func (l *lexer) dateRun(pos int) int {
+	for pos < len(l.input) && (unicode.IsDigit(l.input[pos]) || strings.ContainsRune("./-:", l.input[pos])) {
+		pos++
+	}
+	return pos
+}
+
+// fraction reads the fractional part of the number, if any.
+// The point without digits after it is not a part of the number.
+func (l *lexer) fraction() {
+	if l.pos+1 < len(l.input) && l.input[l.pos] == '.' && unicode.IsDigit(l.input[l.pos+1]) {
+		l.pos++
+		l.skip(unicode.IsDigit)
+	}
+}
+
+// skip moves the position past the runes which satisfy the predicate.
+func (l *lexer) skip(accept func(r rune) bool) {
+	for l.pos < len(l.input) && accept(l.input[l.pos]) {
+		l.pos++
+	}
+}
+
+// quoted reads the string literal in single quotes.
+// Quote inside the literal is escaped by another quote.
+func (l *lexer) quoted(start int) (token, error) {
+	var text []rune
+	l.pos++
+	for l.pos < len(l.input) {
+		r := l.input[l.pos]
+		l.pos++
+		if r != '\'' {
+			text = append(text, r)
+			continue
+		}
+		if l.pos < len(l.input) && l.input[l.pos] == '\'' {
+			text = append(text, r)
+			l.pos++
+			continue
+		}
+		return token{Text: string(text), Kind: tokString, Pos: l.offsets[start]}, nil
+	}
+	return token{}, fmt.Errorf("unterminated string literal at position %d", l.offsets[start])
+}
+
+func (l *lexer) emit(kind tokenKind, start int) token {
+	return token{Text: string(l.input[start:l.pos]), Kind: kind, Pos: l.offsets[start]}
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
+
+func isTwoCharSymbol(first, second rune) bool {
+	switch first {
+	case '>', '!':
+		return second == '='
+	case '<':
+		return second == '=' || second == '>'
+	}
+	return false
+}
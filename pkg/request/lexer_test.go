@@ -0,0 +1,117 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		expect []token
+	}{
+		{
+			name: "criterion",
+			str:  "new_cases >= 4.5",
+			expect: []token{
+				{Text: "new_cases", Kind: tokIdent, Pos: 0},
+				{Text: ">=", Kind: tokSymbol, Pos: 10},
+				{Text: "4.5", Kind: tokNumber, Pos: 13},
+				{Kind: tokEOF, Pos: 16},
+			},
+		},
+		{
+			name: "date",
+			str:  "date<=2020-04-20;",
+			expect: []token{
+				{Text: "date", Kind: tokIdent, Pos: 0},
+				{Text: "<=", Kind: tokSymbol, Pos: 4},
+				{Text: "2020-04-20", Kind: tokDate, Pos: 6},
+				{Text: ";", Kind: tokSymbol, Pos: 16},
+				{Kind: tokEOF, Pos: 17},
+			},
+		},
+		{
+			name: "notEqual",
+			str:  "a!=1 OR b<>2",
+			expect: []token{
+				{Text: "a", Kind: tokIdent, Pos: 0},
+				{Text: "!=", Kind: tokSymbol, Pos: 1},
+				{Text: "1", Kind: tokNumber, Pos: 3},
+				{Text: "OR", Kind: tokIdent, Pos: 5},
+				{Text: "b", Kind: tokIdent, Pos: 8},
+				{Text: "<>", Kind: tokSymbol, Pos: 9},
+				{Text: "2", Kind: tokNumber, Pos: 11},
+				{Kind: tokEOF, Pos: 12},
+			},
+		},
+		{
+			name: "keywordsInsideWords",
+			str:  "ORegon NOTTINGHAM",
+			expect: []token{
+				{Text: "ORegon", Kind: tokIdent, Pos: 0},
+				{Text: "NOTTINGHAM", Kind: tokIdent, Pos: 7},
+				{Kind: tokEOF, Pos: 17},
+			},
+		},
+		{
+			name: "gluedNumber",
+			str:  "45g",
+			expect: []token{
+				{Text: "45g", Kind: tokIdent, Pos: 0},
+				{Kind: tokEOF, Pos: 3},
+			},
+		},
+		{
+			name: "quoted",
+			str:  "location = 'Côte d''Ivoire'",
+			expect: []token{
+				{Text: "location", Kind: tokIdent, Pos: 0},
+				{Text: "=", Kind: tokSymbol, Pos: 9},
+				{Text: "Côte d'Ivoire", Kind: tokString, Pos: 11},
+				{Kind: tokEOF, Pos: 28},
+			},
+		},
+		{
+			name: "multibyte",
+			str:  "город = Москва",
+			expect: []token{
+				{Text: "город", Kind: tokIdent, Pos: 0},
+				{Text: "=", Kind: tokSymbol, Pos: 11},
+				{Text: "Москва", Kind: tokIdent, Pos: 13},
+				{Kind: tokEOF, Pos: 25},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tokens, err := tokenize(tc.str, defaultFormats)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, tokens)
+		})
+	}
+}
+
+func TestTokenizeError(t *testing.T) {
+	_, err := tokenize("location = 'Russia", defaultFormats)
+	assert.EqualError(t, err, "unterminated string literal at position 11")
+}
+
+func TestTokenizeLayoutDates(t *testing.T) {
+	f := newFormats(&Options{DateLayouts: []string{"02.01.2006"}, TimestampLayouts: []string{"01/02/2006 15:04"}})
+	tokens, err := tokenize("d = 30.03.2020 AND t < 03/30/2020 10:30 AND n = 30.03 - 1", f)
+	assert.Nil(t, err)
+
+	var texts []string
+	for _, tok := range tokens[:len(tokens)-1] {
+		texts = append(texts, tok.Text)
+	}
+	expect := []string{"d", "=", "30.03.2020", "AND", "t", "<", "03/30/2020 10:30", "AND", "n", "=", "30.03", "-", "1"}
+	assert.Equal(t, expect, texts)
+	assert.Equal(t, tokDate, tokens[2].Kind)
+	assert.Equal(t, tokDate, tokens[6].Kind)
+	assert.Equal(t, tokNumber, tokens[10].Kind)
+}
@@ -0,0 +1,80 @@
+package request
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Match checks if the value of the expression matches the pattern.
+// LIKE and ILIKE patterns use % for any sequence of characters and _ for
+// a single character, ILIKE ignores the case of letters. REGEXP and ~ use
+// regular expressions which match any part of the value.
+// The pattern is compiled once when the request is parsed.
+type Match struct {
+	Expr     Expression
+	re       *regexp.Regexp
+	Operator string
+	Pattern  string
+	Not      bool
+}
+
+// newMatch compiles the pattern of the operator.
+func newMatch(expr Expression, operator, pattern string, negated bool) (*Match, error) {
+	expression := pattern
+	switch operator {
+	case like:
+		expression = likeToRegexp(pattern)
+	case ilike:
+		expression = "(?i)" + likeToRegexp(pattern)
+	}
+
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("cannot compile pattern of %s operator: %w", operator, err)
+	}
+	return &Match{Expr: expr, re: re, Operator: operator, Pattern: pattern, Not: negated}, nil
+}
+
+// likeToRegexp converts LIKE pattern to the regular expression of the whole value.
+// Backslash escapes % and _, so they can be matched literally.
+func likeToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("(?s)^")
+	runes := []rune(pattern)
+	for ind := 0; ind < len(runes); ind++ {
+		switch r := runes[ind]; {
+		case r == '\\' && ind+1 < len(runes):
+			ind++
+			b.WriteString(regexp.QuoteMeta(string(runes[ind])))
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// GetFields returns fields used in the expression.
+func (m *Match) GetFields() []string {
+	return m.Expr.GetFields()
+}
+
+// String returns the Match condition as it is written in the request.
+func (m *Match) String() string {
+	return fmt.Sprintf("%s %s %s", m.Expr, negatedKeyword(m.Operator, m.Not), &Literal{Value: Data(m.Pattern)})
+}
+
+// Check defines if the value of the expression in the row matches the pattern.
+// The result is Unknown if the value is NULL.
+func (m *Match) Check(row Record) Truth {
+	value := m.Expr.Eval(row)
+	if isNull(value) {
+		return Unknown
+	}
+	return truth(m.re.MatchString(value.String()) != m.Not)
+}
@@ -0,0 +1,67 @@
+package request
+
+import (
+	"context"
+	"errors"
+	"regexp/syntax"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustMatch(expr Expression, operator, pattern string, negated bool) *Match {
+	match, err := newMatch(expr, operator, pattern, negated)
+	if err != nil {
+		panic(err)
+	}
+	return match
+}
+
+func TestMatchCheck(t *testing.T) {
+	index := IndexMap{"value": 0}
+	tests := []struct {
+		name     string
+		operator string
+		pattern  string
+		value    string
+		result   bool
+	}{
+		{name: "likePrefix", operator: like, pattern: "United%", value: "United Arab Emirates", result: true},
+		{name: "likeCase", operator: like, pattern: "united%", value: "United Kingdom", result: false},
+		{name: "likeWhole", operator: like, pattern: "United", value: "United Kingdom", result: false},
+		{name: "likeSingle", operator: like, pattern: "_ussia", value: "Russia", result: true},
+		{name: "likeEscaped", operator: like, pattern: `100\%`, value: "100%", result: true},
+		{name: "likeEscapedMismatch", operator: like, pattern: `100\%`, value: "1000", result: false},
+		{name: "likeMeta", operator: like, pattern: "a.c", value: "abc", result: false},
+		{name: "ilike", operator: ilike, pattern: "%KINGDOM", value: "United Kingdom", result: true},
+		{name: "regexp", operator: matches, pattern: "tests? performed", value: "tests performed", result: true},
+		{name: "regexpPart", operator: matchSymbol, pattern: "^people", value: "units of people", result: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			row := Row{Index: index, Line: []string{tc.value}}
+			assert.Equal(t, truth(tc.result), mustMatch(&Column{Name: "value"}, tc.operator, tc.pattern, false).Check(row))
+			assert.Equal(t, truth(!tc.result), mustMatch(&Column{Name: "value"}, tc.operator, tc.pattern, true).Check(row))
+		})
+	}
+}
+
+func TestNewMatchError(t *testing.T) {
+	_, err := newMatch(&Column{Name: "location"}, matches, "(united", false)
+	var syntaxErr *syntax.Error
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, syntax.ErrMissingParen, syntaxErr.Code)
+}
+
+func TestRequestDoMatch(t *testing.T) {
+	req, err := NewRequest(`SELECT date FROM ./test/owid-covid-data.csv
+	WHERE location LIKE 'Ukr%' AND tests_units ~ 'performed$' AND iso_code NOT ILIKE 'rus';`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 11)
+}
@@ -0,0 +1,59 @@
+package request
+
+import "fmt"
+
+// isNull defines if the value is NULL. Cells with NULL markers are emptied
+// when the line is read, so the marker in the request is the usual string.
+func isNull(value Variable) bool {
+	return value.String() == ""
+}
+
+// clearNullMarkers empties the cells of the line which contain NULL markers, see Options.
+func clearNullMarkers(line, markers []string) {
+	for ind, value := range line {
+		if sliceHasString(value, markers) {
+			line[ind] = ""
+		}
+	}
+}
+
+// Truth is a result of the condition in three-valued logic.
+// The condition over NULL is Unknown, which is neither true nor false,
+// so the row is returned only if the whole condition is True.
+type Truth int8
+
+// Values are ordered, so AND is the minimum and OR is the maximum of the operands.
+const (
+	False Truth = iota
+	Unknown
+	True
+)
+
+func truth(value bool) Truth {
+	if value {
+		return True
+	}
+	return False
+}
+
+// IsNull checks if the value of the expression is NULL or, if Not is set, is not NULL.
+type IsNull struct {
+	Expr Expression
+	Not  bool
+}
+
+// GetFields returns fields used in the expression.
+func (n *IsNull) GetFields() []string {
+	return n.Expr.GetFields()
+}
+
+// String returns the IsNull condition as it is written in the request.
+func (n *IsNull) String() string {
+	return fmt.Sprintf("%s %s %s", n.Expr, kwIs, negatedKeyword(kwNull, n.Not))
+}
+
+// Check defines if the value of the expression in the row is NULL.
+// The result is never Unknown.
+func (n *IsNull) Check(row Record) Truth {
+	return truth(isNull(n.Expr.Eval(row)) != n.Not)
+}
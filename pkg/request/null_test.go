@@ -0,0 +1,140 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogicalCheckUnknown(t *testing.T) {
+	index := IndexMap{"value": 0}
+	row := Row{Index: index, Line: []string{"5"}}
+	conditions := map[Truth]Condition{
+		True:    &Criterion{Left: &Column{Name: "value"}, Symbol: equal, Right: &Literal{Value: Data("5")}},
+		False:   &Criterion{Left: &Column{Name: "value"}, Symbol: equal, Right: &Literal{Value: Data("6")}},
+		Unknown: &Criterion{Left: &Column{Name: "missing"}, Symbol: equal, Right: &Literal{Value: Data("5")}},
+	}
+
+	tests := []struct {
+		name     string
+		left     Truth
+		right    Truth
+		operator string
+		result   Truth
+	}{
+		{name: "unknownAndTrue", left: Unknown, right: True, operator: and, result: Unknown},
+		{name: "unknownAndFalse", left: Unknown, right: False, operator: and, result: False},
+		{name: "falseAndUnknown", left: False, right: Unknown, operator: and, result: False},
+		{name: "unknownOrTrue", left: Unknown, right: True, operator: or, result: True},
+		{name: "unknownOrFalse", left: Unknown, right: False, operator: or, result: Unknown},
+		{name: "trueOrUnknown", left: True, right: Unknown, operator: or, result: True},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			logical := &Logical{Left: conditions[tc.left], Right: conditions[tc.right], Operator: tc.operator}
+			assert.Equal(t, tc.result, logical.Check(row))
+		})
+	}
+}
+
+func TestNullMarkers(t *testing.T) {
+	index := IndexMap{"value": 0}
+	tests := []struct {
+		cond   Condition
+		name   string
+		value  string
+		result Truth
+	}{
+		{name: "isNullEmpty", cond: &IsNull{Expr: &Column{Name: "value"}}, value: "", result: True},
+		{name: "isNullMarker", cond: &IsNull{Expr: &Column{Name: "value"}}, value: "NA", result: True},
+		{name: "isNullValue", cond: &IsNull{Expr: &Column{Name: "value"}}, value: "-5", result: False},
+		{name: "isNotNull", cond: &IsNull{Expr: &Column{Name: "value"}, Not: true}, value: "-", result: False},
+		{
+			name:   "notEqual",
+			cond:   &Criterion{Left: &Column{Name: "value"}, Symbol: notEqual, Right: &Literal{Value: Data("5")}},
+			value:  "NA",
+			result: Unknown,
+		},
+		{
+			name: "notBetween",
+			cond: &Between{
+				Expr: &Column{Name: "value"},
+				Low:  &Literal{Value: Data("1")},
+				High: &Literal{Value: Data("2")},
+				Not:  true,
+			},
+			value:  "",
+			result: Unknown,
+		},
+		{name: "notIn", cond: newIn(&Column{Name: "value"}, true), value: "-", result: Unknown},
+		{name: "notLike", cond: mustMatch(&Column{Name: "value"}, like, "%", true), value: "NA", result: Unknown},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			line := []string{tc.value}
+			clearNullMarkers(line, []string{"NA", "-"})
+			assert.Equal(t, tc.result, tc.cond.Check(Row{Index: index, Line: line}))
+		})
+	}
+}
+
+func TestRequestDoNullMarkers(t *testing.T) {
+	options := &Options{NullMarkers: []string{"-", "d"}}
+	tests := []struct {
+		name   string
+		str    string
+		expect []string
+	}{
+		{
+			name:   "markerCell",
+			str:    "SELECT CONCAT(name, '-') AS tagged FROM ./test/flags.csv",
+			expect: []string{"a-", "b-", "c-", "-"},
+		},
+		{
+			name:   "markerLiteral",
+			str:    "SELECT name AS tagged FROM ./test/flags.csv WHERE name != '-' AND CONCAT('-', '') IS NOT NULL",
+			expect: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest(tc.str, options)
+			assert.NoError(t, err)
+			result, err := req.Do(context.Background(), ",")
+			assert.NoError(t, err)
+
+			values := make([]string, len(result.Data))
+			for ind, data := range result.Data {
+				values[ind] = data["tagged"]
+			}
+			assert.Equal(t, tc.expect, values)
+		})
+	}
+}
+
+func TestRequestDoNull(t *testing.T) {
+	req, err := NewRequest(`SELECT date FROM ./test/owid-covid-data.csv
+	WHERE location = Ukraine AND new_tests IS NULL AND total_tests IS NOT NULL;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{{"date": "2020-04-23"}, {"date": "2020-04-26"}}, result.Data)
+
+	// Empty cells satisfy neither the condition nor its negation.
+	req, err = NewRequest(`SELECT date FROM ./test/owid-covid-data.csv
+	WHERE location = Ukraine AND (total_tests < 90000 OR total_tests NOT BETWEEN 0 AND 90000);`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err = req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 6)
+}
@@ -0,0 +1,151 @@
+package request
+
+import (
+	"strings"
+	"time"
+)
+
+// Options define how the values of the csv files are read and printed.
+// They are passed to NewRequest, so the requests with different options
+// can be done at the same time. The zero value reads the files as they are.
+type Options struct {
+	// NullMarkers are the values of the cells which mean NULL, e.g. "NA" or "-".
+	// Empty cells are always NULL.
+	NullMarkers []string
+	// DateLayouts and TimestampLayouts are additional formats of the dates and
+	// of the timestamps, e.g. "02.01.2006" or "01/02/2006 15:04". Layouts are written
+	// the same way as in the time package. The first matching layout is used.
+	// YYYY-MM-DD dates, RFC3339 and "YYYY-MM-DD hh:mm:ss" timestamps are always known.
+	DateLayouts      []string
+	TimestampLayouts []string
+	// Location is the time zone of the dates and of the timestamps without the offset.
+	// It is UTC if not set.
+	Location *time.Location
+	// TrueValues and FalseValues are additional spellings of the booleans, e.g. "yes"
+	// and "no" or "1" and "0". Spellings are not case sensitive. True and false are
+	// always known. Numbers stay numbers unless the field is declared as BOOL
+	// or converted with CAST.
+	TrueValues  []string
+	FalseValues []string
+	// Precision is the number of digits printed after the point of the floats.
+	// Floats are printed as they are if it is not set. Values stay intact
+	// in the results, so it changes only the output of Print.
+	Precision *int
+	// Schema declares the types of the fields of all csv files, see ParseSchema.
+	// The schema next to the file overrides these types.
+	Schema Schema
+	// StrictTypes makes the request fail on the value which cannot be converted
+	// to the declared type instead of treating it as NULL.
+	StrictTypes bool
+}
+
+// DefaultOptions returns the options which are used if NewRequest gets nil.
+func DefaultOptions() *Options {
+	return &Options{}
+}
+
+// formats are the layouts, the time zone and the spellings of the booleans which
+// define the types of the values, see Options. NewRequest gives them to the columns,
+// the words and the aggregates of the request, so the values of the file and
+// of the request are read the same way.
+type formats struct {
+	dateLayouts      []string
+	timestampLayouts []string
+	location         *time.Location
+	// trueValues and falseValues are the spellings of the booleans in lower case.
+	trueValues  []string
+	falseValues []string
+}
+
+// defaultFormats define the types of Data, which does not depend on the options.
+var defaultFormats = newFormats(DefaultOptions())
+
+// formats returns the formats of the options or nil if they are the default ones.
+func (o *Options) formats() *formats {
+	if o == nil || len(o.DateLayouts) == 0 && len(o.TimestampLayouts) == 0 && len(o.TrueValues) == 0 &&
+		len(o.FalseValues) == 0 && (o.Location == nil || o.Location == time.UTC) {
+		return nil
+	}
+	return newFormats(o)
+}
+
+func newFormats(options *Options) *formats {
+	f := &formats{
+		dateLayouts:      append([]string{dateLayout}, options.DateLayouts...),
+		timestampLayouts: append([]string{timestampLayout, "2006-01-02 15:04:05"}, options.TimestampLayouts...),
+		location:         options.Location,
+		trueValues:       []string{"true"},
+		falseValues:      []string{"false"},
+	}
+	if f.location == nil {
+		f.location = time.UTC
+	}
+	for _, value := range options.TrueValues {
+		f.trueValues = append(f.trueValues, strings.ToLower(value))
+	}
+	for _, value := range options.FalseValues {
+		f.falseValues = append(f.falseValues, strings.ToLower(value))
+	}
+	return f
+}
+
+// parse returns the value of the cell or of the request. The value is Data
+// unless its type depends on the formats, e.g. 18.11.2020 is the date only
+// if its layout is known. Nil formats are the default ones.
+// Numbers stay numbers, but they are booleans too if they are their spellings.
+func (f *formats) parse(str string) Variable {
+	data := Data(str)
+	if f == nil || f == defaultFormats {
+		return data
+	}
+	truth, spelling := f.toBool(str)
+	switch {
+	case data.isFloat() && spelling:
+		return typedData{Data: data, dataType: data.defineType(), spelling: true, truth: truth}
+	case data.isFloat():
+		return data
+	}
+	if date, ok := f.toDate(str); ok {
+		return typedData{Data: data, dataType: date.dataType(), date: date}
+	}
+	if spelling {
+		return typedData{Data: data, dataType: typeBool, spelling: true, truth: truth}
+	}
+	return data
+}
+
+// parseTime parses the value with the first matching layout in the time zone of the formats.
+func (f *formats) parseTime(value string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, f.location); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// toDate returns the date or the timestamp of the value.
+func (f *formats) toDate(value string) (*Date, bool) {
+	if date, ok := f.parseTime(value, f.dateLayouts); ok {
+		return &Date{Time: date}, true
+	}
+	if timestamp, ok := f.parseTime(value, f.timestampLayouts); ok {
+		return &Date{Time: timestamp, Timestamp: true}, true
+	}
+	return nil, false
+}
+
+// toBool returns the boolean of the value and if the value is one of its spellings.
+func (f *formats) toBool(value string) (truth, ok bool) {
+	value = strings.ToLower(value)
+	if sliceHasString(value, f.trueValues) {
+		return true, true
+	}
+	return false, sliceHasString(value, f.falseValues)
+}
+
+// today returns the current date in the time zone of the formats.
+func (f *formats) today() Variable {
+	year, month, day := now().In(f.location).Date()
+	return dateValue(&Date{Time: time.Date(year, month, day, 0, 0, 0, 0, f.location)})
+}
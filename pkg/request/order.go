@@ -0,0 +1,63 @@
+package request
+
+import "sort"
+
+// OrderItem is a single key of the ORDER BY statement.
+// By default NULLs are placed after other values
+// in ascending order and before them in descending order.
+type OrderItem struct {
+	Expr       Expression
+	Descending bool
+	NullsFirst bool
+}
+
+// getOrderFields returns unique fields used in the ORDER BY statement.
+func getOrderFields(items []*OrderItem) []string {
+	var fields []string
+	for _, item := range items {
+		for _, field := range item.Expr.GetFields() {
+			if !sliceHasString(field, fields) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// compare returns negative number if a should be placed before b,
+// positive if after and zero if the order of rows does not matter.
+func (o *OrderItem) compare(a, b RowData, f *formats) int {
+	aValue, bValue := evalData(o.Expr, a, f), evalData(o.Expr, b, f)
+
+	aNull, bNull := isNull(aValue), isNull(bValue)
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull || bNull:
+		if aNull == o.NullsFirst {
+			return -1
+		}
+		return 1
+	}
+
+	result := compareValues(aValue, bValue)
+	if o.Descending {
+		return -result
+	}
+	return result
+}
+
+func (r *Results) sortData() {
+	if len(r.Request.OrderBy) == 0 {
+		return
+	}
+
+	sort.SliceStable(r.Data, func(i, j int) bool {
+		for _, item := range r.Request.OrderBy {
+			if result := item.compare(r.Data[i], r.Data[j], r.Request.formats); result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+}
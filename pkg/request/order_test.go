@@ -0,0 +1,77 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortData(t *testing.T) {
+	data := []RowData{
+		{"location": "Russia", "new_cases": "4268.0", "date": "2020-04-20"},
+		{"location": "Ukraine", "new_cases": "", "date": "2020-04-21"},
+		{"location": "Ukraine", "new_cases": "540", "date": "2020-04-30"},
+		{"location": "Russia", "new_cases": "540.0", "date": "2020-04-22"},
+	}
+
+	tests := []struct {
+		name   string
+		order  []*OrderItem
+		expect []string
+	}{
+		{
+			name:   "ascending",
+			order:  []*OrderItem{{Expr: &Column{Name: "new_cases"}}},
+			expect: []string{"2020-04-30", "2020-04-22", "2020-04-20", "2020-04-21"},
+		},
+		{
+			name:   "descending",
+			order:  []*OrderItem{{Expr: &Column{Name: "new_cases"}, Descending: true, NullsFirst: true}},
+			expect: []string{"2020-04-21", "2020-04-20", "2020-04-30", "2020-04-22"},
+		},
+		{
+			name:   "nullsFirst",
+			order:  []*OrderItem{{Expr: &Column{Name: "new_cases"}, NullsFirst: true}},
+			expect: []string{"2020-04-21", "2020-04-30", "2020-04-22", "2020-04-20"},
+		},
+		{
+			name: "multiColumn",
+			order: []*OrderItem{
+				{Expr: &Column{Name: "location"}, Descending: true}, {Expr: &Column{Name: "date"}, Descending: true},
+			},
+			expect: []string{"2020-04-30", "2020-04-21", "2020-04-22", "2020-04-20"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Results{Request: &Request{OrderBy: tc.order}, Data: append([]RowData{}, data...)}
+			r.sortData()
+
+			var dates []string
+			for _, row := range r.Data {
+				dates = append(dates, row["date"])
+			}
+			assert.Equal(t, tc.expect, dates)
+		})
+	}
+}
+
+func TestRequestDoOrderBy(t *testing.T) {
+	req, err := NewRequest(`SELECT location, new_cases FROM ./test/owid-covid-data.csv
+	WHERE date >= 2020-04-29 ORDER BY date DESC, new_cases;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{
+		{"location": "Ukraine", "new_cases": "540.0", "date": "2020-04-30"},
+		{"location": "Russia", "new_cases": "7099.0", "date": "2020-04-30"},
+		{"location": "Ukraine", "new_cases": "456.0", "date": "2020-04-29"},
+		{"location": "Russia", "new_cases": "5841.0", "date": "2020-04-29"},
+	}, result.Data)
+	assert.Equal(t, IndexMap{"location": 8, "new_cases": 9}, result.MaxLength)
+}
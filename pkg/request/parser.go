@@ -0,0 +1,968 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	kwSelect      string = "SELECT"
+	kwFrom        string = "FROM"
+	kwWhere       string = "WHERE"
+	kwOrder       string = "ORDER"
+	kwBy          string = "BY"
+	kwAsc         string = "ASC"
+	kwDesc        string = "DESC"
+	kwNulls       string = "NULLS"
+	kwFirst       string = "FIRST"
+	kwLast        string = "LAST"
+	kwLimit       string = "LIMIT"
+	kwOffset      string = "OFFSET"
+	kwGroup       string = "GROUP"
+	kwHaving      string = "HAVING"
+	kwDistinct    string = "DISTINCT"
+	kwAs          string = "AS"
+	kwIn          string = "IN"
+	kwBetween     string = "BETWEEN"
+	kwIs          string = "IS"
+	kwNull        string = "NULL"
+	kwCase        string = "CASE"
+	kwWhen        string = "WHEN"
+	kwThen        string = "THEN"
+	kwElse        string = "ELSE"
+	kwEnd         string = "END"
+	kwInterval    string = "INTERVAL"
+	kwCurrentDate string = "CURRENT_DATE"
+	kwCast        string = "CAST"
+	kwDescribe    string = "DESCRIBE"
+	kwTrue        string = "TRUE"
+	kwFalse       string = "FALSE"
+)
+
+// Names of the statements which consist of two keywords.
+const (
+	groupBy string = kwGroup + " " + kwBy
+	orderBy string = kwOrder + " " + kwBy
+)
+
+// clauseKeywords are the keywords which finish the path in FROM statement.
+var clauseKeywords = []string{kwWhere, kwGroup, kwHaving, kwOrder, kwLimit, kwOffset}
+
+var comparisons = []string{equal, notEqual, lessOrGreater, greater, less, greaterOrEqual, lessOrEqual}
+
+// parser is a recursive-descent parser which builds Request from tokens.
+//
+// Grammar:
+//
+//	request   = describe | "SELECT" selection "FROM" ( path | string ) [ "WHERE" condition ]
+//	            [ "GROUP" "BY" expr { "," expr } ] [ "HAVING" condition ] [ "ORDER" "BY" order { "," order } ]
+//	            [ "LIMIT" number ] [ "OFFSET" number ] [ ";" ]
+//	describe  = "DESCRIBE" ( path | string ) [ ";" ]
+//	selection = [ "DISTINCT" ] ( "*" | item { "," item } )
+//	item      = expr [ "AS" ( ident | string ) ]
+//	condition = and { "OR" and }
+//	and       = unary { "AND" unary }
+//	unary     = "NOT" unary | primary
+//	primary   = "(" condition ")" | criterion
+//	criterion = expr ( operator expr | [ "NOT" ] "IN" "(" value { "," value } ")"
+//	            | [ "NOT" ] "BETWEEN" expr "AND" expr | [ "NOT" ] match string | "IS" [ "NOT" ] "NULL" )
+//	operator  = "=" | "!=" | "<>" | ">" | "<" | ">=" | "<=" | "NOT"
+//	match     = "LIKE" | "ILIKE" | "REGEXP" | "~"
+//	expr      = term { ( "+" | "-" ) term }
+//	term      = factor { ( "*" | "/" | "%" ) factor }
+//	factor    = "-" factor | "(" expr ")" | operand
+//	operand   = string | number | date | "TRUE" | "FALSE" | aggregate | cast | call | case | interval
+//	            | "CURRENT_DATE" | ident
+//	aggregate = function "(" ( "*" | [ "DISTINCT" ] ident ) ")"
+//	function  = "COUNT" | "SUM" | "AVG" | "MIN" | "MAX"
+//	cast      = "CAST" "(" expr "AS" type ")"
+//	type      = "INT" | "INTEGER" | "FLOAT" | "DOUBLE" | "STRING" | "TEXT" | "VARCHAR" | "DATE" | "TIMESTAMP"
+//	            | "BOOL" | "BOOLEAN"
+//	call      = ident "(" [ expr { "," expr } ] ")"
+//	interval  = "INTERVAL" string
+//	case      = "CASE" "WHEN" condition "THEN" expr { "WHEN" condition "THEN" expr } [ "ELSE" expr ] "END"
+//	value     = string | [ "-" ] word { word }
+//	order     = expr [ "ASC" | "DESC" ] [ "NULLS" ( "FIRST" | "LAST" ) ]
+//
+// On the right side of the operator the sequence of bare words, e.g. United Arab Emirates,
+// is a single value. A single word is the field if the file has such header, see Word.
+type parser struct {
+	input  string
+	clause string
+	tokens []token
+	// aggregates are collected from SELECT, HAVING and ORDER BY statements.
+	aggregates []*Aggregate
+	// words are collected from the value side of the conditions.
+	words []*Word
+	// columns are collected from all statements.
+	columns []*Column
+	// formats define the types of the values of the request, see Options.
+	formats *formats
+	pos     int
+}
+
+// references are the nodes of the request which should be resolved
+// against the headers of the file.
+type references struct {
+	words   []*Word
+	columns []*Column
+}
+
+// parseRequest returns the Request together with the bare words of its conditions
+// and its columns, which should be resolved against the headers of the file.
+// Values are parsed with the given formats or with the default ones if they are nil.
+func parseRequest(str string, f *formats) (*Request, *references, error) {
+	if f == nil {
+		f = defaultFormats
+	}
+	tokens, err := tokenize(str, f)
+	if err != nil {
+		return nil, nil, err
+	}
+	p := &parser{input: str, tokens: tokens, formats: f}
+
+	r, err := p.parseRequest()
+	if err != nil {
+		return nil, nil, err
+	}
+	return r, &references{words: p.words, columns: p.columns}, nil
+}
+
+func (p *parser) parseRequest() (*Request, error) {
+	if p.acceptKeyword(kwDescribe) {
+		return p.parseDescribe()
+	}
+
+	r := &Request{}
+	// Clauses are read in the order they are written in the request.
+	clauses := []func(r *Request) error{
+		p.parseSelection, p.parseFrom, p.parseWhere, p.parseGroup, p.parseHaving, p.parseOrder, p.parseLimit,
+	}
+	for _, parse := range clauses {
+		if err := parse(r); err != nil {
+			return nil, err
+		}
+	}
+	for _, agg := range p.aggregates {
+		if r.getAggregate(agg.String()) == nil {
+			r.Aggregates = append(r.Aggregates, agg)
+		}
+	}
+
+	if err := p.parseEnd(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// parseDescribe reads the path of DESCRIBE request.
+func (p *parser) parseDescribe() (*Request, error) {
+	path, err := p.parsePath(kwDescribe)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.parseEnd(); err != nil {
+		return nil, err
+	}
+	return &Request{From: path, Describe: true}, nil
+}
+
+// parseEnd checks that nothing but the semicolon follows the request.
+func (p *parser) parseEnd() error {
+	p.acceptSymbol(";")
+	if tok := p.peek(); tok.Kind != tokEOF {
+		return fmt.Errorf("unexpected %s at position %d", tok, tok.Pos)
+	}
+	return nil
+}
+
+func (p *parser) parseFrom(r *Request) error {
+	if !p.acceptKeyword(kwFrom) {
+		return errors.New("cannot find FROM in your request")
+	}
+	var err error
+	r.From, err = p.parsePath(kwFrom)
+	return err
+}
+
+func (p *parser) parseWhere(r *Request) error {
+	if !p.acceptKeyword(kwWhere) {
+		return nil
+	}
+	p.clause = kwWhere
+	var err error
+	r.Where, err = p.parseCondition()
+	return err
+}
+
+func (p *parser) parseGroup(r *Request) error {
+	if !p.acceptKeyword(kwGroup) {
+		return nil
+	}
+	p.clause = groupBy
+	var err error
+	r.GroupBy, err = p.parseGroupBy()
+	return err
+}
+
+func (p *parser) parseHaving(r *Request) error {
+	if !p.acceptKeyword(kwHaving) {
+		return nil
+	}
+	p.clause = kwHaving
+	var err error
+	r.Having, err = p.parseCondition()
+	return err
+}
+
+func (p *parser) parseOrder(r *Request) error {
+	if !p.acceptKeyword(kwOrder) {
+		return nil
+	}
+	p.clause = orderBy
+	var err error
+	r.OrderBy, err = p.parseOrderBy()
+	return err
+}
+
+// parseLimit reads LIMIT and OFFSET.
+func (p *parser) parseLimit(r *Request) error {
+	if p.acceptKeyword(kwLimit) {
+		limit, err := p.parseCount(kwLimit)
+		if err != nil {
+			return err
+		}
+		r.Limit = &limit
+	}
+	if !p.acceptKeyword(kwOffset) {
+		return nil
+	}
+	var err error
+	r.Offset, err = p.parseCount(kwOffset)
+	return err
+}
+
+func (p *parser) parseSelection(r *Request) error {
+	if !p.acceptKeyword(kwSelect) {
+		return errors.New("cannot find SELECT in your request")
+	}
+	p.clause = kwSelect
+	r.Distinct = p.acceptKeyword(kwDistinct)
+	if p.acceptSymbol(allFields) {
+		r.Select = []*SelectItem{{Expr: &Column{Name: allFields}}}
+		return nil
+	}
+
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return err
+		}
+		r.Select = append(r.Select, item)
+
+		if !p.acceptSymbol(",") {
+			return nil
+		}
+	}
+}
+
+func (p *parser) parseSelectItem() (*SelectItem, error) {
+	expr, err := p.parseExpression(false)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &SelectItem{Expr: expr}
+	if p.acceptKeyword(kwAs) {
+		alias := p.peek()
+		if alias.Kind != tokString && (alias.Kind != tokIdent || isKeyword(alias.Text)) {
+			return nil, fmt.Errorf("unexpected %s after AS, expected alias", alias)
+		}
+		p.advance()
+		item.Alias = alias.Text
+	}
+	return item, nil
+}
+
+func (p *parser) parseAggregate(function string) (*Aggregate, error) {
+	if !sliceHasString(function, aggregateFunctions) {
+		return nil, fmt.Errorf("unknown aggregate function: %s", function)
+	}
+
+	agg := &Aggregate{Function: function}
+	if p.acceptKeyword(kwDistinct) {
+		if function != aggCount {
+			return nil, fmt.Errorf("DISTINCT is supported only in %s function", aggCount)
+		}
+		agg.Distinct = true
+	}
+
+	if !p.acceptAggregateField(agg) {
+		return nil, fmt.Errorf("unexpected %s in %s function, expected field name", p.peek(), function)
+	}
+	if !p.acceptSymbol(")") {
+		return nil, fmt.Errorf("unexpected %s in %s function, expected \")\"", p.peek(), function)
+	}
+	p.aggregates = append(p.aggregates, agg)
+	return agg, nil
+}
+
+// acceptAggregateField accepts the field of the aggregate or * of COUNT(*).
+func (p *parser) acceptAggregateField(agg *Aggregate) bool {
+	tok := p.peek()
+	allRows := tok.Kind == tokSymbol && tok.Text == allFields && agg.Function == aggCount && !agg.Distinct
+	if !allRows && (tok.Kind != tokIdent || isKeyword(tok.Text)) {
+		return false
+	}
+	p.advance()
+	agg.Field = tok.Text
+	return true
+}
+
+// parsePath reads the path to the csv file after the keyword. It takes the raw part
+// of the request string until the next clause, since paths can contain any characters
+// which are not the part of the grammar.
+func (p *parser) parsePath(keyword string) (string, error) {
+	start := p.peek()
+	if start.Kind == tokString {
+		p.advance()
+		return start.Text, nil
+	}
+	for {
+		tok := p.peek()
+		if tok.Kind == tokEOF || tok.Kind == tokSymbol && tok.Text == ";" || p.isClauseKeyword(tok) {
+			path := strings.TrimSpace(p.input[start.Pos:tok.Pos])
+			if path == "" {
+				return "", fmt.Errorf("cannot find path to csv file after %s", keyword)
+			}
+			return path, nil
+		}
+		p.advance()
+	}
+}
+
+func (p *parser) parseCondition() (Condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword(or) {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Left: left, Right: right, Operator: or}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword(and) {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Left: left, Right: right, Operator: and}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Condition, error) {
+	if !p.acceptKeyword(not) {
+		return p.parsePrimary()
+	}
+
+	cond, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return &Negation{Condition: cond}, nil
+}
+
+func (p *parser) parsePrimary() (Condition, error) {
+	if tok := p.peek(); tok.Kind != tokSymbol || tok.Text != "(" {
+		return p.parseCriterion()
+	}
+
+	start, aggregates, words, columns := p.pos, len(p.aggregates), len(p.words), len(p.columns)
+	p.advance()
+	cond, err := p.parseCondition()
+	if err == nil {
+		if !p.acceptSymbol(")") {
+			return nil, fmt.Errorf("unexpected %s in %s statement, expected \")\"", p.peek(), p.clause)
+		}
+		if !isOperator(p.peek()) {
+			return cond, nil
+		}
+	}
+
+	// The bracket can also open the arithmetic expression, e.g. (a + b) * 2 > c.
+	p.pos, p.aggregates, p.words, p.columns = start, p.aggregates[:aggregates], p.words[:words], p.columns[:columns]
+	crit, critErr := p.parseCriterion()
+	if critErr != nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, critErr
+	}
+	return crit, nil
+}
+
+func (p *parser) parseCriterion() (Condition, error) {
+	left, err := p.parseExpression(false)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword(kwIs) {
+		return p.parseIsNull(left)
+	}
+
+	negated := p.acceptNegation()
+	switch symbol := p.peek(); {
+	case p.acceptKeyword(kwIn):
+		return p.parseIn(newIn(left, negated))
+	case p.acceptKeyword(kwBetween):
+		return p.parseBetween(left, negated)
+	case isMatchOperator(symbol):
+		p.advance()
+		return p.parseMatch(left, symbol.Text, negated)
+	}
+	return p.parseComparison(left)
+}
+
+// parseIsNull reads NULL or NOT NULL after IS.
+func (p *parser) parseIsNull(expr Expression) (*IsNull, error) {
+	cond := &IsNull{Expr: expr, Not: p.acceptKeyword(not)}
+	if !p.acceptKeyword(kwNull) {
+		return nil, fmt.Errorf("unexpected %s after IS, expected NULL or NOT NULL", p.peek())
+	}
+	return cond, nil
+}
+
+// acceptNegation accepts NOT before IN, BETWEEN and the match operators.
+// Otherwise NOT is the comparison operator, see parseComparison.
+func (p *parser) acceptNegation() bool {
+	if tok := p.peekNext(); tok.Kind == tokIdent && (tok.Text == kwIn || tok.Text == kwBetween) || isMatchOperator(tok) {
+		return p.acceptKeyword(not)
+	}
+	return false
+}
+
+// parseComparison reads the comparison operator and the value compared with the expression.
+func (p *parser) parseComparison(left Expression) (*Criterion, error) {
+	symbol := p.peek()
+	switch {
+	case symbol.Kind == tokSymbol && sliceHasString(symbol.Text, comparisons):
+		p.advance()
+	case symbol.Kind == tokIdent && symbol.Text == not:
+		// "field NOT value" is the old form of "field != value".
+		p.advance()
+		symbol.Text = notEqual
+	default:
+		return nil, fmt.Errorf("unexpected %s in %s statement, expected comparison operator", symbol, p.clause)
+	}
+	if symbol.Text == lessOrGreater {
+		symbol.Text = notEqual
+	}
+
+	// Quoted literals are compared exactly.
+	quoted := p.peek().Kind == tokString
+	right, err := p.parseExpression(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Criterion{Left: left, Right: right, Symbol: symbol.Text, CaseSensitive: quoted}, nil
+}
+
+// parseMatch reads the pattern of LIKE, ILIKE, REGEXP or ~ operator.
+func (p *parser) parseMatch(expr Expression, operator string, negated bool) (*Match, error) {
+	tok := p.peek()
+	if tok.Kind != tokString {
+		return nil, fmt.Errorf("unexpected %s after %s, expected quoted pattern", tok, operator)
+	}
+	p.advance()
+	return newMatch(expr, operator, tok.Text, negated)
+}
+
+// parseIn reads the list of values of IN operator.
+func (p *parser) parseIn(in *In) (*In, error) {
+	if !p.acceptSymbol("(") {
+		return nil, fmt.Errorf("unexpected %s after IN, expected \"(\"", p.peek())
+	}
+	for {
+		if tok := p.peek(); tok.Kind == tokString {
+			p.advance()
+			in.add(p.formats.parse(tok.Text), true)
+		} else {
+			prefix := ""
+			if p.acceptSymbol(minus) {
+				prefix = minus
+			}
+			value := p.parseWords()
+			if value == "" {
+				return nil, fmt.Errorf("unexpected %s in IN list, expected value", p.peek())
+			}
+			in.add(p.formats.parse(prefix+value), false)
+		}
+
+		if p.acceptSymbol(")") {
+			return in, nil
+		}
+		if !p.acceptSymbol(",") {
+			return nil, fmt.Errorf("unexpected %s in IN list, expected \")\"", p.peek())
+		}
+	}
+}
+
+// parseBetween reads the bounds of BETWEEN operator.
+func (p *parser) parseBetween(expr Expression, negated bool) (*Between, error) {
+	low, err := p.parseExpression(true)
+	if err != nil {
+		return nil, err
+	}
+	if !p.acceptKeyword(and) {
+		return nil, fmt.Errorf("unexpected %s in BETWEEN operator, expected AND", p.peek())
+	}
+	high, err := p.parseExpression(true)
+	if err != nil {
+		return nil, err
+	}
+	return &Between{Expr: expr, Low: low, High: high, Not: negated}, nil
+}
+
+// parseExpression reads the arithmetic expression. If values is set,
+// bare words are read as the values instead of the fields.
+func (p *parser) parseExpression(values bool) (Expression, error) {
+	left, err := p.parseTerm(values)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		if op.Kind != tokSymbol || op.Text != plus && op.Text != minus {
+			return left, nil
+		}
+		p.advance()
+
+		right, err := p.parseTerm(values)
+		if err != nil {
+			return nil, err
+		}
+		left = &Arithmetic{Left: left, Right: right, Operator: op.Text}
+	}
+}
+
+func (p *parser) parseTerm(values bool) (Expression, error) {
+	left, err := p.parseFactor(values)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op := p.peek()
+		if op.Kind != tokSymbol || op.Text != multiply && op.Text != divide && op.Text != modulo {
+			return left, nil
+		}
+		p.advance()
+
+		right, err := p.parseFactor(values)
+		if err != nil {
+			return nil, err
+		}
+		left = &Arithmetic{Left: left, Right: right, Operator: op.Text}
+	}
+}
+
+func (p *parser) parseFactor(values bool) (Expression, error) {
+	switch {
+	case p.acceptSymbol(minus):
+		return p.parseNegative(values)
+	case p.acceptSymbol("("):
+		return p.parseParenthesised(values)
+	}
+	return p.parseOperand(values)
+}
+
+// parseNegative reads the operand of the unary minus.
+func (p *parser) parseNegative(values bool) (Expression, error) {
+	expr, err := p.parseFactor(values)
+	if err != nil {
+		return nil, err
+	}
+	// Numbers are negated in place, so -5 is the literal and - -5 is 5.
+	if lit, ok := expr.(*Literal); ok && lit.Value.isFloat() {
+		str := lit.Value.String()
+		if strings.HasPrefix(str, minus) {
+			return &Literal{Value: Data(str[len(minus):])}, nil
+		}
+		return &Literal{Value: Data(minus + str)}, nil
+	}
+	return &Negative{Expr: expr}, nil
+}
+
+// parseParenthesised reads the expression in brackets after the opening one.
+func (p *parser) parseParenthesised(values bool) (Expression, error) {
+	expr, err := p.parseExpression(values)
+	if err != nil {
+		return nil, err
+	}
+	if !p.acceptSymbol(")") {
+		return nil, fmt.Errorf("unexpected %s in %s statement, expected \")\"", p.peek(), p.clause)
+	}
+	return expr, nil
+}
+
+// keywordOperands are the keywords which start the operands, see parseKeywordOperand.
+var keywordOperands = map[string]bool{kwCase: true, kwCurrentDate: true, kwInterval: true}
+
+// parseOperand reads the literal, the operand which starts with the keyword,
+// the function call, the value or the column.
+func (p *parser) parseOperand(values bool) (Expression, error) {
+	tok := p.peek()
+	switch {
+	case tok.Kind == tokString || p.atBoolean():
+		p.advance()
+		return &Literal{Value: p.formats.parse(tok.Text)}, nil
+	case tok.Kind == tokIdent && keywordOperands[tok.Text]:
+		p.advance()
+		return p.parseKeywordOperand(tok.Text, values)
+	case tok.Kind == tokEOF || tok.Kind == tokSymbol || isKeyword(tok.Text):
+		return nil, p.operandError(values)
+	case p.atCall():
+		p.advance()
+		p.advance()
+		return p.parseCall(tok.Text, values)
+	case values:
+		return p.parseValue(), nil
+	}
+	return p.parseField(), nil
+}
+
+// operandError reports the unexpected token instead of the operand.
+func (p *parser) operandError(values bool) error {
+	if values {
+		return fmt.Errorf("unexpected %s in %s statement, expected value", p.peek(), p.clause)
+	}
+	return fmt.Errorf("unexpected %s in %s statement, expected field name", p.peek(), p.clause)
+}
+
+// atBoolean defines if the next token is TRUE or FALSE, which is not a part of the value of several words.
+func (p *parser) atBoolean() bool {
+	tok := p.peek()
+	return tok.Kind == tokIdent && (tok.Text == kwTrue || tok.Text == kwFalse) && !isWord(p.peekNext())
+}
+
+// atCall defines if the next tokens are the name of the function and the opening bracket.
+func (p *parser) atCall() bool {
+	next := p.peekNext()
+	return p.peek().Kind == tokIdent && next.Kind == tokSymbol && next.Text == "("
+}
+
+// parseKeywordOperand reads the operand after its keyword, see keywordOperands.
+func (p *parser) parseKeywordOperand(keyword string, values bool) (Expression, error) {
+	switch keyword {
+	case kwCase:
+		return p.parseCase(values)
+	case kwInterval:
+		return p.parseInterval()
+	}
+	return &CurrentDate{Value: p.formats.today()}, nil
+}
+
+// parseValue reads the bare word, which is resolved as the field if the file has
+// such header, or the value of several words.
+func (p *parser) parseValue() Expression {
+	tok := p.peek()
+	if tok.Kind != tokIdent || isWord(p.peekNext()) {
+		return &Literal{Value: p.formats.parse(strings.ToLower(p.parseWords()))}
+	}
+	p.advance()
+	word := &Word{Text: tok.Text}
+	p.words = append(p.words, word)
+	return word
+}
+
+// parseField reads the column, or the literal if the token is not the name, e.g. the number.
+func (p *parser) parseField() Expression {
+	tok := p.peek()
+	p.advance()
+	if tok.Kind != tokIdent {
+		return &Literal{Value: p.formats.parse(tok.Text)}
+	}
+	column := &Column{Name: tok.Text}
+	p.columns = append(p.columns, column)
+	return column
+}
+
+// parseCall reads the arguments of the aggregate or scalar function.
+func (p *parser) parseCall(name string, values bool) (Expression, error) {
+	switch {
+	case name == kwCast:
+		return p.parseCast(values)
+	case !sliceHasString(name, aggregateFunctions):
+		return p.parseFunction(name, values)
+	case p.clause != kwSelect && p.clause != kwHaving && p.clause != orderBy:
+		return nil, fmt.Errorf("aggregate function %s is not allowed in %s statement", name, p.clause)
+	}
+	return p.parseAggregate(name)
+}
+
+// parseFunction reads the arguments of the scalar function and checks their number.
+func (p *parser) parseFunction(name string, values bool) (*Function, error) {
+	fn, ok := scalarFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function: %s", name)
+	}
+	args, err := p.parseArgs(name, values)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) < fn.minArgs || fn.maxArgs >= 0 && len(args) > fn.maxArgs {
+		return nil, fmt.Errorf("wrong number of arguments in %s function: %d", name, len(args))
+	}
+	return &Function{Name: name, Args: args}, nil
+}
+
+// parseArgs reads the arguments of the function until the closing bracket.
+func (p *parser) parseArgs(name string, values bool) ([]Expression, error) {
+	var args []Expression
+	for !p.acceptSymbol(")") {
+		if len(args) > 0 && !p.acceptSymbol(",") {
+			return nil, fmt.Errorf("unexpected %s in %s function, expected \")\"", p.peek(), name)
+		}
+		arg, err := p.parseExpression(values)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// parseCast reads the expression and the type of CAST.
+func (p *parser) parseCast(values bool) (*Cast, error) {
+	expr, err := p.parseExpression(values)
+	if err != nil {
+		return nil, err
+	}
+	if !p.acceptKeyword(kwAs) {
+		return nil, fmt.Errorf("unexpected %s in %s function, expected AS", p.peek(), kwCast)
+	}
+	tok := p.peek()
+	dataType, ok := typeNames[tok.Text]
+	if tok.Kind != tokIdent || !ok {
+		return nil, fmt.Errorf("unknown type in %s function: %s", kwCast, tok)
+	}
+	p.advance()
+	if !p.acceptSymbol(")") {
+		return nil, fmt.Errorf("unexpected %s in %s function, expected \")\"", p.peek(), kwCast)
+	}
+	return &Cast{Expr: expr, Type: dataType}, nil
+}
+
+// parseCase reads the branches of the CASE expression.
+func (p *parser) parseCase(values bool) (*Case, error) {
+	c := &Case{}
+	for p.acceptKeyword(kwWhen) {
+		cond, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptKeyword(kwThen) {
+			return nil, fmt.Errorf("unexpected %s in CASE expression, expected THEN", p.peek())
+		}
+		result, err := p.parseExpression(values)
+		if err != nil {
+			return nil, err
+		}
+		c.Whens = append(c.Whens, &When{Condition: cond, Result: result})
+	}
+	if len(c.Whens) == 0 {
+		return nil, fmt.Errorf("unexpected %s in CASE expression, expected WHEN", p.peek())
+	}
+
+	if p.acceptKeyword(kwElse) {
+		result, err := p.parseExpression(values)
+		if err != nil {
+			return nil, err
+		}
+		c.Else = result
+	}
+	if !p.acceptKeyword(kwEnd) {
+		return nil, fmt.Errorf("unexpected %s in CASE expression, expected END", p.peek())
+	}
+	return c, nil
+}
+
+// parseInterval reads the quoted interval, e.g. '7 days'.
+func (p *parser) parseInterval() (*Interval, error) {
+	tok := p.peek()
+	if tok.Kind != tokString {
+		return nil, fmt.Errorf("unexpected %s after INTERVAL, expected quoted interval", tok)
+	}
+	p.advance()
+	return newInterval(tok.Text)
+}
+
+// parseWords reads the value which is not wrapped in quotes.
+func (p *parser) parseWords() string {
+	var words []string
+	for {
+		tok := p.peek()
+		if !isWord(tok) {
+			return strings.Join(words, " ")
+		}
+		p.advance()
+		words = append(words, tok.Text)
+	}
+}
+
+func (p *parser) parseGroupBy() ([]Expression, error) {
+	if !p.acceptKeyword(kwBy) {
+		return nil, fmt.Errorf("unexpected %s after GROUP, expected BY", p.peek())
+	}
+
+	var exprs []Expression
+	for {
+		expr, err := p.parseExpression(false)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+
+		if !p.acceptSymbol(",") {
+			return exprs, nil
+		}
+	}
+}
+
+func (p *parser) parseOrderBy() ([]*OrderItem, error) {
+	if !p.acceptKeyword(kwBy) {
+		return nil, fmt.Errorf("unexpected %s after ORDER, expected BY", p.peek())
+	}
+
+	var items []*OrderItem
+	for {
+		expr, err := p.parseExpression(false)
+		if err != nil {
+			return nil, err
+		}
+
+		item := &OrderItem{Expr: expr}
+		if p.acceptKeyword(kwDesc) {
+			item.Descending = true
+		} else {
+			p.acceptKeyword(kwAsc)
+		}
+		item.NullsFirst = item.Descending
+		if p.acceptKeyword(kwNulls) {
+			switch {
+			case p.acceptKeyword(kwFirst):
+				item.NullsFirst = true
+			case p.acceptKeyword(kwLast):
+				item.NullsFirst = false
+			default:
+				return nil, fmt.Errorf("unexpected %s after NULLS, expected FIRST or LAST", p.peek())
+			}
+		}
+		items = append(items, item)
+
+		if !p.acceptSymbol(",") {
+			return items, nil
+		}
+	}
+}
+
+// parseCount reads non-negative integer value of LIMIT or OFFSET.
+func (p *parser) parseCount(clause string) (int, error) {
+	tok := p.peek()
+	if tok.Kind == tokNumber {
+		if count, err := strconv.Atoi(tok.Text); err == nil {
+			p.advance()
+			return count, nil
+		}
+	}
+	return 0, fmt.Errorf("unexpected %s in %s statement, expected non-negative integer", tok, clause)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekNext() token {
+	if p.pos+1 < len(p.tokens) {
+		return p.tokens[p.pos+1]
+	}
+	return p.peek()
+}
+
+func (p *parser) advance() {
+	if p.tokens[p.pos].Kind != tokEOF {
+		p.pos++
+	}
+}
+
+func (p *parser) acceptKeyword(kw string) bool {
+	if tok := p.peek(); tok.Kind == tokIdent && tok.Text == kw {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if tok := p.peek(); tok.Kind == tokSymbol && tok.Text == symbol {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) isClauseKeyword(tok token) bool {
+	return tok.Kind == tokIdent && sliceHasString(tok.Text, clauseKeywords)
+}
+
+// isWord defines if the token can be a part of the value which is not wrapped in quotes.
+func isWord(tok token) bool {
+	return (tok.Kind == tokIdent || tok.Kind == tokNumber || tok.Kind == tokDate) && !isKeyword(tok.Text)
+}
+
+// operatorWords are the key words of the operators, except for the match operators.
+var operatorWords = []string{not, kwIn, kwBetween, kwIs}
+
+// isOperator defines if the token continues the expression,
+// so it cannot follow the condition in brackets.
+func isOperator(tok token) bool {
+	if tok.Kind == tokSymbol && (sliceHasString(tok.Text, comparisons) || sliceHasString(tok.Text, arithmeticOperators)) {
+		return true
+	}
+	return tok.Kind == tokIdent && sliceHasString(tok.Text, operatorWords) || isMatchOperator(tok)
+}
+
+// matchWords are the key words of the match operators, see Match.
+var matchWords = []string{like, ilike, matches}
+
+func isMatchOperator(tok token) bool {
+	return tok.Kind == tokSymbol && tok.Text == matchSymbol || tok.Kind == tokIdent && sliceHasString(tok.Text, matchWords)
+}
+
+func isKeyword(word string) bool {
+	switch word {
+	case kwSelect, kwDistinct, kwAs, kwFrom, kwWhere, kwGroup, kwHaving, kwOrder, kwLimit, kwOffset,
+		kwIn, kwBetween, kwIs, kwNull, kwCase, kwWhen, kwThen, kwElse, kwEnd, kwInterval, kwCurrentDate,
+		and, or, not, like, ilike, matches:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,471 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testLimit = 20
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		expect *Request
+		name   string
+		str    string
+	}{
+		{
+			name:   "noWhere",
+			str:    "SELECT * FROM ./test/owid-covid-data.csv;",
+			expect: &Request{Select: selectColumns("*"), From: "./test/owid-covid-data.csv"},
+		},
+		{
+			name:   "pathWithSpaces",
+			str:    "SELECT location, date FROM ./my data/covid-2020.csv",
+			expect: &Request{Select: selectColumns("location", "date"), From: "./my data/covid-2020.csv"},
+		},
+		{
+			name: "keywordsInsideValues",
+			str:  "SELECT location FROM file.csv WHERE location = ORegon OR location = NOTTINGHAM;",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Where: &Logical{
+					Left:     &Criterion{Left: &Column{Name: "location"}, Symbol: equal, Right: &Word{Text: "ORegon"}},
+					Right:    &Criterion{Left: &Column{Name: "location"}, Symbol: equal, Right: &Word{Text: "NOTTINGHAM"}},
+					Operator: or,
+				},
+			},
+		},
+		{
+			name: "quoted",
+			str:  "SELECT location FROM './my data.csv' WHERE location = 'United Arab Emirates' OR location = North  America",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "./my data.csv",
+				Where: &Logical{
+					Left: &Criterion{
+						Left:          &Column{Name: "location"},
+						Right:         &Literal{Value: Data("United Arab Emirates")},
+						Symbol:        equal,
+						CaseSensitive: true,
+					},
+					Right: &Criterion{
+						Left: &Column{Name: "location"}, Symbol: equal, Right: &Literal{Value: Data("north america")},
+					},
+					Operator: or,
+				},
+			},
+		},
+		{
+			name: "ilike",
+			str:  "SELECT location FROM file.csv WHERE location ILIKE 'RUSSIA' OR tests_units NOT ~ 'people|tests'",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Where: &Logical{
+					Left:     mustMatch(&Column{Name: "location"}, ilike, "RUSSIA", false),
+					Right:    mustMatch(&Column{Name: "tests_units"}, matchSymbol, "people|tests", true),
+					Operator: or,
+				},
+			},
+		},
+		{
+			name: "precedence",
+			str:  "SELECT location FROM file.csv WHERE a = 1 OR b = 2 AND (c = 3 OR d = 4)",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Where: &Logical{
+					Left: &Criterion{Left: &Column{Name: "a"}, Symbol: equal, Right: &Literal{Value: Data("1")}},
+					Right: &Logical{
+						Left: &Criterion{Left: &Column{Name: "b"}, Symbol: equal, Right: &Literal{Value: Data("2")}},
+						Right: &Logical{
+							Left:     &Criterion{Left: &Column{Name: "c"}, Symbol: equal, Right: &Literal{Value: Data("3")}},
+							Right:    &Criterion{Left: &Column{Name: "d"}, Symbol: equal, Right: &Literal{Value: Data("4")}},
+							Operator: or,
+						},
+						Operator: and,
+					},
+					Operator: or,
+				},
+			},
+		},
+		{
+			name: "orderBy",
+			str:  "SELECT location FROM file.csv ORDER BY new_cases DESC, date ASC NULLS FIRST, location;",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				OrderBy: []*OrderItem{
+					{Expr: &Column{Name: "new_cases"}, Descending: true, NullsFirst: true},
+					{Expr: &Column{Name: "date"}, NullsFirst: true},
+					{Expr: &Column{Name: "location"}},
+				},
+			},
+		},
+		{
+			name: "limitOffset",
+			str:  "SELECT location FROM file.csv LIMIT 20 OFFSET 5",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Limit:  &testLimit,
+				Offset: 5,
+			},
+		},
+		{
+			name: "groupBy",
+			str:  "SELECT location, COUNT(*), SUM(new_cases) FROM file.csv GROUP BY location, iso_code",
+			expect: &Request{
+				Select: []*SelectItem{
+					{Expr: &Column{Name: "location"}},
+					{Expr: &Aggregate{Function: aggCount, Field: "*"}},
+					{Expr: &Aggregate{Function: aggSum, Field: "new_cases"}},
+				},
+				Aggregates: []*Aggregate{
+					{Function: aggCount, Field: "*"},
+					{Function: aggSum, Field: "new_cases"},
+				},
+				From:    "file.csv",
+				GroupBy: []Expression{&Column{Name: "location"}, &Column{Name: "iso_code"}},
+			},
+		},
+		{
+			name: "aliases",
+			str:  "SELECT location AS country, SUM(new_cases) AS 'total cases' FROM file.csv GROUP BY location",
+			expect: &Request{
+				Select: []*SelectItem{
+					{Expr: &Column{Name: "location"}, Alias: "country"},
+					{Expr: &Aggregate{Function: aggSum, Field: "new_cases"}, Alias: "total cases"},
+				},
+				Aggregates: []*Aggregate{{Function: aggSum, Field: "new_cases"}},
+				From:       "file.csv",
+				GroupBy:    []Expression{&Column{Name: "location"}},
+			},
+		},
+		{
+			name: "case",
+			str: `SELECT CASE WHEN new_cases > 5000 THEN 'high' ELSE 'low' END AS level, COUNT(*) FROM file.csv
+			GROUP BY level ORDER BY COALESCE(new_tests, 0) DESC`,
+			expect: &Request{
+				Select: []*SelectItem{
+					{
+						Expr: &Case{
+							Whens: []*When{{
+								Condition: &Criterion{
+									Left:   &Column{Name: "new_cases"},
+									Right:  &Literal{Value: Data("5000")},
+									Symbol: greater,
+								},
+								Result: &Literal{Value: Data("high")},
+							}},
+							Else: &Literal{Value: Data("low")},
+						},
+						Alias: "level",
+					},
+					{Expr: &Aggregate{Function: aggCount, Field: "*"}},
+				},
+				Aggregates: []*Aggregate{{Function: aggCount, Field: "*"}},
+				From:       "file.csv",
+				GroupBy:    []Expression{&Column{Name: "level"}},
+				OrderBy: []*OrderItem{{
+					Expr: &Function{
+						Name: "COALESCE",
+						Args: []Expression{&Column{Name: "new_tests"}, &Literal{Value: Data("0")}},
+					},
+					Descending: true,
+					NullsFirst: true,
+				}},
+			},
+		},
+		{
+			name: "cast",
+			str:  "SELECT CAST(new_cases AS INTEGER) AS cases FROM file.csv WHERE CAST(iso_code AS STRING) = '007'",
+			expect: &Request{
+				Select: []*SelectItem{{
+					Expr:  &Cast{Expr: &Column{Name: "new_cases"}, Type: typeInteger},
+					Alias: "cases",
+				}},
+				From: "file.csv",
+				Where: &Criterion{
+					Left:          &Cast{Expr: &Column{Name: "iso_code"}, Type: typeString},
+					Right:         &Literal{Value: Data("007")},
+					Symbol:        equal,
+					CaseSensitive: true,
+				},
+			},
+		},
+		{
+			name: "bool",
+			str:  "SELECT location FROM file.csv WHERE CAST(new_tests AS BOOLEAN) = FALSE",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Where: &Criterion{
+					Left:   &Cast{Expr: &Column{Name: "new_tests"}, Type: typeBool},
+					Right:  &Literal{Value: Data(kwFalse)},
+					Symbol: equal,
+				},
+			},
+		},
+		{
+			name:   "describe",
+			str:    "DESCRIBE ./path/to/file.csv;",
+			expect: &Request{From: "./path/to/file.csv", Describe: true},
+		},
+		{
+			name: "arithmetic",
+			str:  "SELECT new_deaths / new_cases * 100 AS cfr FROM file.csv WHERE (total_deaths + 1) * 1000 > -5",
+			expect: &Request{
+				Select: []*SelectItem{{
+					Expr: &Arithmetic{
+						Left:     &Arithmetic{Left: &Column{Name: "new_deaths"}, Right: &Column{Name: "new_cases"}, Operator: divide},
+						Right:    &Literal{Value: Data("100")},
+						Operator: multiply,
+					},
+					Alias: "cfr",
+				}},
+				From: "file.csv",
+				Where: &Criterion{
+					Left: &Arithmetic{
+						Left:     &Arithmetic{Left: &Column{Name: "total_deaths"}, Right: &Literal{Value: Data("1")}, Operator: plus},
+						Right:    &Literal{Value: Data("1000")},
+						Operator: multiply,
+					},
+					Right:  &Literal{Value: Data("-5")},
+					Symbol: greater,
+				},
+			},
+		},
+		{
+			name: "in",
+			str: "SELECT location FROM file.csv WHERE location IN ('Russia', united arab emirates) " +
+				"AND new_cases NOT IN (-5, 10)",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Where: &Logical{
+					Left: &In{
+						Expr:   &Column{Name: "location"},
+						set:    map[string]bool{"Russia": true, "united arab emirates": false},
+						values: []string{"'Russia'", "united arab emirates"},
+					},
+					Right: &In{
+						Expr:   &Column{Name: "new_cases"},
+						set:    map[string]bool{"-5.0": false, "-5": false, "10.0": false, "10": false},
+						values: []string{"-5", "10"},
+						Not:    true,
+					},
+					Operator: and,
+				},
+			},
+		},
+		{
+			name: "between",
+			str: "SELECT location FROM file.csv WHERE date NOT BETWEEN 2020-04-20 AND 2020-04-30 " +
+				"AND new_cases BETWEEN -5 AND 10 * 2",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Where: &Logical{
+					Left: &Between{
+						Expr: &Column{Name: "date"},
+						Low:  &Literal{Value: Data("2020-04-20")},
+						High: &Literal{Value: Data("2020-04-30")},
+						Not:  true,
+					},
+					Right: &Between{
+						Expr: &Column{Name: "new_cases"},
+						Low:  &Literal{Value: Data("-5")},
+						High: &Arithmetic{Left: &Literal{Value: Data("10")}, Right: &Literal{Value: Data("2")}, Operator: multiply},
+					},
+					Operator: and,
+				},
+			},
+		},
+		{
+			name: "notEqual",
+			str:  "SELECT location FROM file.csv WHERE NOT (a != 1 OR NOT b <> 2) AND c = 3",
+			expect: &Request{
+				Select: selectColumns("location"),
+				From:   "file.csv",
+				Where: &Logical{
+					Left: &Negation{Condition: &Logical{
+						Left: &Criterion{Left: &Column{Name: "a"}, Symbol: notEqual, Right: &Literal{Value: Data("1")}},
+						Right: &Negation{
+							Condition: &Criterion{Left: &Column{Name: "b"}, Symbol: notEqual, Right: &Literal{Value: Data("2")}},
+						},
+						Operator: or,
+					}},
+					Right:    &Criterion{Left: &Column{Name: "c"}, Symbol: equal, Right: &Literal{Value: Data("3")}},
+					Operator: and,
+				},
+			},
+		},
+		{
+			name: "fieldWithKeyword",
+			str:  "SELECT ANDORRA_cases FROM file.csv WHERE ANDORRA_cases NOT -5",
+			expect: &Request{
+				Select: selectColumns("ANDORRA_cases"),
+				From:   "file.csv",
+				Where:  &Criterion{Left: &Column{Name: "ANDORRA_cases"}, Symbol: notEqual, Right: &Literal{Value: Data("-5")}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, _, err := parseRequest(tc.str, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expect, req)
+		})
+	}
+}
+
+func TestParseRequestError(t *testing.T) {
+	tests := []TestError{
+		{
+			name:      "noPath",
+			reqString: "SELECT location FROM WHERE location = russia",
+			err:       "cannot find path to csv file after FROM",
+		},
+		{
+			name:      "noValue",
+			reqString: "SELECT location FROM file.csv WHERE location = AND date > 2020-04-20",
+			err:       `unexpected "AND" in WHERE statement, expected value`,
+		},
+		{
+			name:      "unclosedBracket",
+			reqString: "SELECT location FROM file.csv WHERE (location = russia OR date > 2020-04-20",
+			err:       `unexpected end of request in WHERE statement, expected ")"`,
+		},
+		{
+			name:      "orderWithoutBy",
+			reqString: "SELECT location FROM file.csv ORDER date",
+			err:       `unexpected "date" after ORDER, expected BY`,
+		},
+		{
+			name:      "negativeLimit",
+			reqString: "SELECT location FROM file.csv LIMIT -1",
+			err:       `unexpected "-" in LIMIT statement, expected non-negative integer`,
+		},
+		{
+			name:      "sumAll",
+			reqString: "SELECT SUM(*) FROM file.csv",
+			err:       `unexpected "*" in SUM function, expected field name`,
+		},
+		{
+			name:      "unknownFunction",
+			reqString: "SELECT MEDIAN(new_cases) FROM file.csv",
+			err:       "unknown function: MEDIAN",
+		},
+		{
+			name:      "sumDistinct",
+			reqString: "SELECT SUM(DISTINCT new_cases) FROM file.csv",
+			err:       "DISTINCT is supported only in COUNT function",
+		},
+		{
+			name:      "emptySelect",
+			reqString: "SELECT FROM file.csv",
+			err:       `unexpected "FROM" in SELECT statement, expected field name`,
+		},
+		{
+			name:      "unclosedExpression",
+			reqString: "SELECT (new_deaths + new_cases FROM file.csv",
+			err:       `unexpected "FROM" in SELECT statement, expected ")"`,
+		},
+		{
+			name:      "inWithoutBrackets",
+			reqString: "SELECT location FROM file.csv WHERE location IN russia",
+			err:       `unexpected "russia" after IN, expected "("`,
+		},
+		{
+			name:      "unclosedIn",
+			reqString: "SELECT location FROM file.csv WHERE location IN (russia, ukraine",
+			err:       `unexpected end of request in IN list, expected ")"`,
+		},
+		{
+			name:      "emptyIn",
+			reqString: "SELECT location FROM file.csv WHERE location IN ()",
+			err:       `unexpected ")" in IN list, expected value`,
+		},
+		{
+			name:      "betweenWithoutAnd",
+			reqString: "SELECT location FROM file.csv WHERE date BETWEEN 2020-04-20 OR 2020-04-30",
+			err:       `unexpected "OR" in BETWEEN operator, expected AND`,
+		},
+		{
+			name:      "unquotedPattern",
+			reqString: "SELECT location FROM file.csv WHERE location LIKE United",
+			err:       `unexpected "United" after LIKE, expected quoted pattern`,
+		},
+		{
+			name:      "invalidRegexp",
+			reqString: "SELECT location FROM file.csv WHERE location REGEXP '(united'",
+			err:       "cannot compile pattern of REGEXP operator: error parsing regexp: missing closing ): `(united`",
+		},
+		{
+			name:      "isWithoutNull",
+			reqString: "SELECT location FROM file.csv WHERE location IS russia",
+			err:       `unexpected "russia" after IS, expected NULL or NOT NULL`,
+		},
+		{
+			name:      "emptyAlias",
+			reqString: "SELECT location AS FROM file.csv",
+			err:       `unexpected "FROM" after AS, expected alias`,
+		},
+		{
+			name:      "caseWithoutWhen",
+			reqString: "SELECT CASE new_cases END FROM file.csv",
+			err:       `unexpected "new_cases" in CASE expression, expected WHEN`,
+		},
+		{
+			name:      "caseWithoutThen",
+			reqString: "SELECT CASE WHEN new_cases > 5000 'high' END FROM file.csv",
+			err:       `unexpected "high" in CASE expression, expected THEN`,
+		},
+		{
+			name:      "caseWithoutEnd",
+			reqString: "SELECT CASE WHEN new_cases > 5000 THEN 'high' ELSE 'low' FROM file.csv",
+			err:       `unexpected "FROM" in CASE expression, expected END`,
+		},
+		{
+			name:      "aggregateInGroupBy",
+			reqString: "SELECT location FROM file.csv GROUP BY SUM(new_cases)",
+			err:       "aggregate function SUM is not allowed in GROUP BY statement",
+		},
+		{
+			name:      "unquotedInterval",
+			reqString: "SELECT location FROM file.csv WHERE date > CURRENT_DATE - INTERVAL 7",
+			err:       `unexpected "7" after INTERVAL, expected quoted interval`,
+		},
+		{
+			name:      "castWithoutAs",
+			reqString: "SELECT CAST(new_cases INT) FROM file.csv",
+			err:       `unexpected "INT" in CAST function, expected AS`,
+		},
+		{
+			name:      "castUnknownType",
+			reqString: "SELECT CAST(new_cases AS NUMBER) FROM file.csv",
+			err:       `unknown type in CAST function: "NUMBER"`,
+		},
+		{
+			name:      "describeWithoutPath",
+			reqString: "DESCRIBE ;",
+			err:       "cannot find path to csv file after DESCRIBE",
+		},
+		{
+			name:      "trailingTokens",
+			reqString: "SELECT location FROM file.csv WHERE location = russia; date",
+			err:       `unexpected "date" at position 55`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := parseRequest(tc.reqString, nil)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
@@ -6,101 +6,146 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strings"
 )
 
 const (
 	and            string = "AND"
 	or             string = "OR"
 	not            string = "NOT"
+	like           string = "LIKE"
+	ilike          string = "ILIKE"
+	matches        string = "REGEXP"
+	matchSymbol    string = "~"
 	equal          string = "="
+	notEqual       string = "!="
+	lessOrGreater  string = "<>"
 	greater        string = ">"
 	less           string = "<"
 	greaterOrEqual string = ">="
 	lessOrEqual    string = "<="
 )
 
-type symbols []string
-
-func (s symbols) List() []string {
-	return []string{and, or, not, greaterOrEqual, lessOrEqual, equal, greater, less}
-}
-
 // Request is a struct which defines main parameters of the request:
-// select, from and where.
+// select, from, where, group by, having, order by, limit and offset.
+// Distinct is set if only unique selected rows should be returned.
+// Aggregates are all aggregate functions used in select, having and order by.
+// Limit is nil if the request has no limit.
+// Schema is nil if the types of the fields are not declared.
+// Describe is set for DESCRIBE request, which reports the fields of the file instead.
 type Request struct {
-	Where  *Criterion
-	From   string
-	Select []string
+	Where      Condition
+	Having     Condition
+	Limit      *int
+	From       string
+	Select     []*SelectItem
+	Aggregates []*Aggregate
+	GroupBy    []Expression
+	OrderBy    []*OrderItem
+	Schema     Schema
+	Offset     int
+	Distinct   bool
+	Describe   bool
+	options    *Options
+	formats    *formats
 }
 
 // NewRequest parses the given string and returns Request object.
-func NewRequest(str string) (*Request, error) {
-	r := &Request{}
-	preparedStr := removeCharacters(str, " \n\t;")
-
-	fromIndex, whereIndex, err := getIndexes(preparedStr)
+// The request reads the files with the given options, see DefaultOptions if they are nil.
+func NewRequest(str string, options *Options) (*Request, error) {
+	f := options.formats()
+	r, refs, err := parseRequest(str, f)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", err, str)
 	}
+	r.options, r.formats = options, f
 
-	fromFile := fmt.Sprint(preparedStr[fromIndex+4 : whereIndex])
-	r.From = fromFile
-	headers, err := getHeaders(fromFile)
+	headers, err := getHeaders(r.From)
 	if err != nil {
 		return nil, err
 	}
-
-	reqSelect := fmt.Sprint(preparedStr[6:fromIndex])
-	var selectItems []string
-	switch reqSelect {
-	case "*":
-		selectItems = headers
-	default:
-		selectItems = strings.Split(reqSelect, ",")
-		for _, key := range selectItems {
-			if !sliceHasString(key, headers) {
-				return nil, fmt.Errorf("cannot find selected option: %s in headers: %v", key, headers)
-			}
-		}
+	if r.Schema, err = loadSchema(r.From, headers, r.getOptions().Schema); err != nil {
+		return nil, err
 	}
-	r.Select = selectItems
-
-	if whereIndex != len(preparedStr) {
-		criterions, err := parseWhere(fmt.Sprint(preparedStr[whereIndex+5:]))
-		if err != nil {
-			return nil, err
-		}
-		fields := criterions.GetFields()
-		if err := checkWhere(headers, fields); err != nil {
-			return nil, err
-		}
-		r.Where = criterions
+	if r.Describe {
+		r.Select = selectNames(describeNames)
+		return r, nil
 	}
+	r.resolveReferences(refs, headers)
+	r.expandAllFields(headers)
+	r.resolveGroupAliases(headers)
+	r.resolveOrderAliases()
 
+	if err := r.check(headers); err != nil {
+		return nil, err
+	}
 	return r, nil
 }
 
-func getIndexes(preparedStr string) (int, int, error) {
-	if !strings.Contains(preparedStr, "SELECT") {
-		return -1, -1, errors.New("cannot find SELECT in your request")
+// resolveReferences gives the words and the columns of the request the declared
+// types of the fields, and the formats of the request to the aggregates too.
+// Words become fields if the file has such headers.
+func (r *Request) resolveReferences(refs *references, headers []string) {
+	for _, word := range refs.words {
+		word.Field = sliceHasString(word.Text, headers)
+		if word.Field {
+			word.Type = r.Schema[word.Text]
+		}
+		word.formats = r.formats
+	}
+	for _, column := range refs.columns {
+		column.Type, column.formats = r.Schema[column.Name], r.formats
+	}
+	for _, agg := range r.Aggregates {
+		agg.formats = r.formats
 	}
+}
 
-	fromIndex := strings.Index(preparedStr, "FROM")
-	if fromIndex == -1 {
-		return -1, -1, errors.New("cannot find FROM in your request")
+// expandAllFields replaces * in SELECT with the columns of all fields of the file.
+func (r *Request) expandAllFields(headers []string) {
+	if len(r.Select) != 1 || r.Select[0].Expr.String() != allFields {
+		return
+	}
+	r.Select = make([]*SelectItem, len(headers))
+	for ind, header := range headers {
+		r.Select[ind] = &SelectItem{Expr: &Column{Name: header, Type: r.Schema[header], formats: r.formats}}
 	}
+}
 
-	if !strings.Contains(preparedStr, "WHERE") {
-		return fromIndex, len(preparedStr), nil
+// check checks that the fields of every clause can be found in the headers.
+func (r *Request) check(headers []string) error {
+	if err := r.checkAggregation(headers); err != nil {
+		return err
+	}
+	if err := r.checkSelect(headers); err != nil {
+		return err
+	}
+	if err := r.checkHaving(); err != nil {
+		return err
+	}
+	if r.Where != nil {
+		if err := checkWhere(headers, r.Where.GetFields()); err != nil {
+			return err
+		}
 	}
+	return r.checkOrder(headers)
+}
 
-	whereIndex := strings.Index(preparedStr, "WHERE")
-	if whereIndex == -1 {
-		return -1, -1, errors.New("cannot find WHERE in your request")
+func (r *Request) checkSelect(headers []string) error {
+	for _, key := range getSelectFields(r.Select) {
+		if r.getAggregate(key) == nil && !sliceHasString(key, headers) {
+			return fmt.Errorf("cannot find selected option: %s in headers: %v", key, headers)
+		}
 	}
+	return nil
+}
 
-	return fromIndex, whereIndex, nil
+// getOptions returns the options of the request or the default ones
+// if the request is not created with NewRequest.
+func (r *Request) getOptions() *Options {
+	if r.options == nil {
+		return DefaultOptions()
+	}
+	return r.options
 }
 
 func checkWhere(headers, fields []string) error {
@@ -121,9 +166,196 @@ func checkWhere(headers, fields []string) error {
 	return nil
 }
 
+// resolveGroupAliases replaces the aliases of the selected items in GROUP BY
+// with their expressions, unless the file has the field with the same name.
+func (r *Request) resolveGroupAliases(headers []string) {
+	for ind, expr := range r.GroupBy {
+		column, ok := expr.(*Column)
+		if !ok || sliceHasString(column.Name, headers) {
+			continue
+		}
+		for _, item := range r.Select {
+			if item.Alias == column.Name {
+				r.GroupBy[ind] = item.Expr
+				break
+			}
+		}
+	}
+}
+
+// fieldValue returns the value of the field in the row of the file, see fieldValue.
+func (r *Request) fieldValue(field string, data RowData) Variable {
+	return fieldValue(data[field], r.Schema[field], r.formats)
+}
+
+// resolveOrderAliases gives the aliases in ORDER BY the declared types of the selected
+// expressions, so the rows are sorted the same way by the alias and by the expression.
+func (r *Request) resolveOrderAliases() {
+	for _, item := range r.OrderBy {
+		column, ok := item.Expr.(*Column)
+		if !ok {
+			continue
+		}
+		for _, selected := range r.Select {
+			if selected.Alias == column.Name {
+				column.Type = declaredType(selected.Expr)
+				break
+			}
+		}
+	}
+}
+
+func (r *Request) checkAggregation(headers []string) error {
+	if err := r.checkGroupFields(headers); err != nil {
+		return err
+	}
+	if !r.aggregated() {
+		return nil
+	}
+	for _, item := range r.Select {
+		if key := r.ungroupedField(item.Expr, nil); key != "" {
+			return fmt.Errorf("selected option: %s should be used in GROUP BY or in aggregate function", key)
+		}
+	}
+	return nil
+}
+
+// checkGroupFields checks that the fields of the aggregates and of GROUP BY are in headers.
+func (r *Request) checkGroupFields(headers []string) error {
+	for _, agg := range r.Aggregates {
+		if agg.Field != allFields && !sliceHasString(agg.Field, headers) {
+			return fmt.Errorf("cannot find %s option: %s in headers: %v", agg.Function, agg.Field, headers)
+		}
+	}
+	for _, expr := range r.GroupBy {
+		for _, key := range expr.GetFields() {
+			if !sliceHasString(key, headers) {
+				return fmt.Errorf("cannot find group option: %s in headers: %v", key, headers)
+			}
+		}
+	}
+	return nil
+}
+
+// ungroupedField returns the field which prevents the expression from being computed
+// from the row of the group, or empty string if there is no such field. The expression
+// can be the GROUP BY expression itself or use GROUP BY expressions, aggregates
+// and the given names, which are already computed in the row.
+func (r *Request) ungroupedField(expr Expression, names []string) string {
+	groupNames := r.groupNames()
+	if sliceHasString(expr.String(), groupNames) {
+		return ""
+	}
+	for _, key := range expr.GetFields() {
+		if r.getAggregate(key) == nil && !sliceHasString(key, groupNames) && !sliceHasString(key, names) {
+			return key
+		}
+	}
+	return ""
+}
+
+// groupNames returns the names of GROUP BY expressions in the rows of the groups.
+func (r *Request) groupNames() []string {
+	names := make([]string, len(r.GroupBy))
+	for ind, expr := range r.GroupBy {
+		names[ind] = expr.String()
+	}
+	return names
+}
+
+func (r *Request) checkHaving() error {
+	if r.Having == nil {
+		return nil
+	}
+	if !r.aggregated() {
+		return errors.New("HAVING can be used only with GROUP BY or aggregate functions")
+	}
+
+	names := append(getSelectNames(r.Select), r.groupNames()...)
+	for _, key := range r.Having.GetFields() {
+		if r.getAggregate(key) == nil && !sliceHasString(key, names) {
+			return fmt.Errorf("having option: %s should be used in GROUP BY or in aggregate function", key)
+		}
+	}
+	return nil
+}
+
+// checkOrder checks that rows can be sorted by the ORDER BY expressions.
+// Rows can always be sorted by the selected names, e.g. aliases.
+// Other fields should be in headers, or in GROUP BY if the request is aggregated.
+func (r *Request) checkOrder(headers []string) error {
+	names := getSelectNames(r.Select)
+	for _, item := range r.OrderBy {
+		key := item.Expr.String()
+		switch {
+		case sliceHasString(key, names):
+			continue
+		case r.Distinct:
+			return fmt.Errorf("order option: %s should be selected in DISTINCT request", key)
+		}
+
+		if r.aggregated() {
+			if field := r.ungroupedField(item.Expr, names); field != "" {
+				return fmt.Errorf("order option: %s should be used in GROUP BY", field)
+			}
+			continue
+		}
+		for _, field := range item.Expr.GetFields() {
+			if !sliceHasString(field, names) && !sliceHasString(field, headers) {
+				return fmt.Errorf("cannot find order option: %s in headers: %v", field, headers)
+			}
+		}
+	}
+	return nil
+}
+
+// aggregated defines if the rows of the request should be grouped.
+func (r *Request) aggregated() bool {
+	return len(r.Aggregates) > 0 || len(r.GroupBy) > 0
+}
+
+func (r *Request) getAggregate(name string) *Aggregate {
+	for _, agg := range r.Aggregates {
+		if agg.String() == name {
+			return agg
+		}
+	}
+	return nil
+}
+
+// dataFields returns fields of the file which are required
+// to select, sort, group or aggregate the rows.
+func (r *Request) dataFields() []string {
+	var fields []string
+	for _, key := range getSelectFields(r.Select) {
+		if r.getAggregate(key) == nil {
+			fields = append(fields, key)
+		}
+	}
+	groupFields := make([]string, 0, len(r.GroupBy))
+	for _, expr := range r.GroupBy {
+		groupFields = append(groupFields, expr.GetFields()...)
+	}
+	for _, key := range append(getOrderFields(r.OrderBy), groupFields...) {
+		if !sliceHasString(key, fields) {
+			fields = append(fields, key)
+		}
+	}
+	for _, agg := range r.Aggregates {
+		if agg.Field != allFields && !sliceHasString(agg.Field, fields) {
+			fields = append(fields, agg.Field)
+		}
+	}
+	return fields
+}
+
 // Do starts the request to a csv file with the request object.
+// If the deadline of the context is exceeded, the rows read before it are returned
+// together with the error. The results are nil on other errors.
 func (r *Request) Do(ctx context.Context, csvSep string) (*Results, error) {
-	reqResult := &Results{Request: r}
+	if r.Describe {
+		return r.describe(ctx, csvSep)
+	}
 
 	resultDataCh := make(chan RowData, 1)
 	defer close(resultDataCh)
@@ -135,17 +367,47 @@ func (r *Request) Do(ctx context.Context, csvSep string) (*Results, error) {
 	if err != nil {
 		return nil, err
 	}
+	reqResult := r.newResults(headers)
+
+	go reqResult.ParseCSVFile(ctx, csvSep, resultDataCh, doneCh)
+
+	for {
+		select {
+		case resultData := <-resultDataCh:
+			reqResult.collect(resultData)
+		case err := <-doneCh:
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return nil, err
+			}
+			// Scanner could send the last rows right before it finished.
+			for len(resultDataCh) > 0 {
+				reqResult.collect(<-resultDataCh)
+			}
+			reqResult.finish()
+			return reqResult, err
+		}
+	}
+}
 
+// newResults returns the empty results of the request with the indexes
+// of the fields in the lines of the file with the given headers.
+func (r *Request) newResults(headers []string) *Results {
+	reqResult := &Results{Request: r}
 	fieldsInd := make(IndexMap)
 	maxLength := make(IndexMap)
+	for _, name := range getSelectNames(r.Select) {
+		maxLength[name] = len(name)
+	}
+	dataFields := r.dataFields()
 	for ind, val := range headers {
-		for _, field := range r.Select {
-			if val == field {
-				fieldsInd[field] = ind
-				maxLength[field] = len(field)
-			}
+		if sliceHasString(val, dataFields) {
+			fieldsInd[val] = ind
 		}
 	}
+	if r.aggregated() {
+		reqResult.aggregator = newAggregator(r)
+	}
+	reqResult.fillTypedFields(headers)
 
 	reqResult.Lock()
 	reqResult.SelectInd = fieldsInd
@@ -154,30 +416,13 @@ func (r *Request) Do(ctx context.Context, csvSep string) (*Results, error) {
 	reqResult.fillConditionIndexes(headers)
 	reqResult.Unlock()
 	reqResult.HasData = true
-
-	go reqResult.ParseCSVFile(ctx, csvSep, resultDataCh, doneCh)
-
-	for {
-		select {
-		case resultData := <-resultDataCh:
-			reqResult.Data = append(reqResult.Data, resultData)
-		case err := <-doneCh:
-			if err != nil {
-				return reqResult, err
-			}
-			return reqResult, nil
-		}
-	}
+	return reqResult
 }
 
-func removeCharacters(input string, characters string) string {
-	filter := func(r rune) rune {
-		if !strings.ContainsRune(characters, r) {
-			return r
-		}
-		return -1
-	}
-	return strings.Map(filter, input)
+// streamed defines if offset and limit can be applied right during the file
+// scanning, so the scan can be stopped as soon as the limit is reached.
+func (r *Request) streamed() bool {
+	return len(r.OrderBy) == 0 && !r.aggregated()
 }
 
 func getHeaders(csvFile string) ([]string, error) {
@@ -205,51 +450,3 @@ func sliceHasString(key string, arr []string) bool {
 	}
 	return false
 }
-
-func parseWhere(where string) (*Criterion, error) {
-	if kw, ok := hasKeyword(where); ok {
-		return define(where, kw), nil
-	}
-
-	return nil, fmt.Errorf("cannot find any keyword in WHERE statement: %s", where)
-}
-
-func define(where string, sep string) *Criterion {
-	sepIndex := strings.Index(where, sep)
-
-	mainWhere := where[:sepIndex]
-	restWhere := where[sepIndex+len(sep):]
-
-	if mainSep, ok := hasKeyword(mainWhere); ok {
-		mainCriterion := define(mainWhere, mainSep)
-
-		if restSep, ok := hasKeyword(restWhere); ok {
-			crit := define(restWhere, restSep)
-
-			cond := &Condition{}
-			switch sep {
-			case and:
-				crit.Strict = true
-				cond.And = crit
-			case or:
-				cond.Or = crit
-			}
-
-			criterionWoCondition := mainCriterion.getLastCondition()
-			criterionWoCondition.Conditions = cond
-		}
-		return mainCriterion
-	}
-
-	return &Criterion{Field: mainWhere, Symbol: sep, Value: Data(strings.ToLower(restWhere)), Strict: false}
-}
-
-func hasKeyword(str string) (string, bool) {
-	var s symbols
-	for _, kw := range s.List() {
-		if strings.Contains(str, kw) {
-			return kw, true
-		}
-	}
-	return "", false
-}
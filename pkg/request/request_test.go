@@ -1,21 +1,20 @@
 package request
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func TestRemoveCharacters(t *testing.T) {
-	output := removeCharacters("SELECT test FROM some/place WHERE something;", " ")
-	assert.Equal(t, output, "SELECTtestFROMsome/placeWHEREsomething;")
-}
-
 func TestSliceHasKey(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -35,31 +34,12 @@ func TestSliceHasKey(t *testing.T) {
 	}
 }
 
-func TestHasKeyword(t *testing.T) {
-	tests := []struct {
-		name   string
-		str    string
-		kw     string
-		result bool
-	}{
-		{name: "hasAnd", str: "something AND something", kw: and, result: true},
-		{name: "hasOr", str: "something OR something", kw: or, result: true},
-		{name: "hasNot", str: "something NOT something", kw: not, result: true},
-		{name: "hasEqual", str: "something = something", kw: equal, result: true},
-		{name: "hasGreater", str: "something > something", kw: greater, result: true},
-		{name: "hasLess", str: "something < something", kw: less, result: true},
-		{name: "hasGreaterOrEqual", str: "something >= something", kw: greaterOrEqual, result: true},
-		{name: "hasLessOrEqual", str: "something <= something", kw: lessOrEqual, result: true},
-		{name: "no", str: "something something", kw: "", result: false},
-	}
-
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			kw, result := hasKeyword(tc.str)
-			assert.Equal(t, kw, tc.kw)
-			assert.Equal(t, result, tc.result)
-		})
+func selectColumns(names ...string) []*SelectItem {
+	items := make([]*SelectItem, len(names))
+	for ind, name := range names {
+		items[ind] = &SelectItem{Expr: &Column{Name: name}}
 	}
+	return items
 }
 
 func TestNewRequest(t *testing.T) {
@@ -67,41 +47,25 @@ func TestNewRequest(t *testing.T) {
 	FROM ./test/owid-covid-data.csv
 	WHERE location = Ukraine OR location = Russia OR location = United Arab Emirates AND new_cases > 0;
 	`
-	req, err := NewRequest(requestString)
+	req, err := NewRequest(requestString, nil)
 	assert.Nil(t, err)
 	want := &Request{
-		Select: []string{"location", "new_cases", "date"},
+		Select: selectColumns("location", "new_cases", "date"),
 		From:   "./test/owid-covid-data.csv",
-		Where: &Criterion{
-			Field: "location",
-			Conditions: &Condition{
-				Or: &Criterion{
-					Field: "location",
-					Conditions: &Condition{
-						Or: &Criterion{
-							Field: "location",
-							Conditions: &Condition{
-								And: &Criterion{
-									Field:      "new_cases",
-									Conditions: nil,
-									Symbol:     ">",
-									Value:      Data("0"),
-									Strict:     true,
-								},
-							},
-							Symbol: "=",
-							Value:  Data("unitedarabemirates"),
-							Strict: false,
-						},
-					},
-					Symbol: "=",
-					Value:  Data("russia"),
-					Strict: false,
+		Where: &Logical{
+			Left: &Logical{
+				Left:     &Criterion{Left: &Column{Name: "location"}, Symbol: "=", Right: &Word{Text: "Ukraine"}},
+				Right:    &Criterion{Left: &Column{Name: "location"}, Symbol: "=", Right: &Word{Text: "Russia"}},
+				Operator: or,
+			},
+			Right: &Logical{
+				Left: &Criterion{
+					Left: &Column{Name: "location"}, Symbol: "=", Right: &Literal{Value: Data("united arab emirates")},
 				},
+				Right:    &Criterion{Left: &Column{Name: "new_cases"}, Symbol: ">", Right: &Literal{Value: Data("0")}},
+				Operator: and,
 			},
-			Symbol: "=",
-			Value:  Data("ukraine"),
-			Strict: false,
+			Operator: or,
 		},
 	}
 	if !reflect.DeepEqual(req, want) {
@@ -117,33 +81,19 @@ func TestNewRequest(t *testing.T) {
 	requestString = `SELECT * 
 	FROM ./test/owid-covid-data.csv
 	WHERE location = Ukraine OR location = Russia AND new_cases > 0;`
-	req, err = NewRequest(requestString)
+	req, err = NewRequest(requestString, nil)
 	assert.Nil(t, err)
 	want = &Request{
-		Select: headers,
+		Select: selectColumns(headers...),
 		From:   "./test/owid-covid-data.csv",
-		Where: &Criterion{
-			Field: "location",
-			Conditions: &Condition{
-				Or: &Criterion{
-					Field: "location",
-					Conditions: &Condition{
-						And: &Criterion{
-							Field:      "new_cases",
-							Conditions: nil,
-							Symbol:     ">",
-							Value:      Data("0"),
-							Strict:     true,
-						},
-					},
-					Symbol: "=",
-					Value:  Data("russia"),
-					Strict: false,
-				},
+		Where: &Logical{
+			Left: &Criterion{Left: &Column{Name: "location"}, Symbol: "=", Right: &Word{Text: "Ukraine"}},
+			Right: &Logical{
+				Left:     &Criterion{Left: &Column{Name: "location"}, Symbol: "=", Right: &Word{Text: "Russia"}},
+				Right:    &Criterion{Left: &Column{Name: "new_cases"}, Symbol: ">", Right: &Literal{Value: Data("0")}},
+				Operator: and,
 			},
-			Symbol: "=",
-			Value:  Data("ukraine"),
-			Strict: false,
+			Operator: or,
 		},
 	}
 	if !reflect.DeepEqual(req, want) {
@@ -174,7 +124,7 @@ func TestGetIndexes(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			req, err := NewRequest(tc.reqString)
+			req, err := NewRequest(tc.reqString, nil)
 			assert.Nil(t, req)
 			assert.Equal(t, err.Error(), fmt.Sprintf("%s: %s", tc.err, tc.reqString))
 		})
@@ -182,7 +132,7 @@ func TestGetIndexes(t *testing.T) {
 }
 
 func TestNewRequestError(t *testing.T) {
-	req, err := NewRequest("SELECT something FROM somewhere WHERE something")
+	req, err := NewRequest("SELECT something FROM somewhere WHERE something = 1", nil)
 	assert.Nil(t, req)
 	assert.Equal(t, err.Error(), "open somewhere: no such file or directory")
 }
@@ -212,14 +162,15 @@ func TestNotInHeaders(t *testing.T) {
 		},
 		{
 			name:      "noKeywordInWhere",
-			reqString: "SELECT location FROM ./test/owid-covid-data.csv WHERE location IS russia;",
-			err:       "cannot find any keyword in WHERE statement: locationISrussia",
+			reqString: "SELECT location FROM ./test/owid-covid-data.csv WHERE location HAS russia;",
+			err: "unexpected \"HAS\" in WHERE statement, expected comparison operator: " +
+				"SELECT location FROM ./test/owid-covid-data.csv WHERE location HAS russia;",
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			_, err := NewRequest(tc.reqString)
+			_, err := NewRequest(tc.reqString, nil)
 			assert.Equal(t, err.Error(), tc.err)
 		})
 	}
@@ -229,10 +180,10 @@ func TestRequestDo(t *testing.T) {
 	requestString := `
 	SELECT location, new_cases, date 
 	FROM ./test/owid-covid-data.csv
-	WHERE location = Russia OR location = Ukraine AND date >= 2020-04-20 AND date <= 2020-04-30 
+	WHERE (location = Russia OR location = Ukraine) AND date >= 2020-04-20 AND date <= 2020-04-30
 	AND date NOT 2020-04-23 AND new_cases > 500 AND new_cases < 5500;
 	`
-	req, err := NewRequest(requestString)
+	req, err := NewRequest(requestString, nil)
 	if err != nil {
 		t.Errorf("error: %s", err)
 	}
@@ -272,7 +223,7 @@ func TestDoCtxTimeout(t *testing.T) {
 	FROM ./test/owid-covid-data.csv
 	WHERE new_cases > 0;
 	`
-	req, err := NewRequest(requestString)
+	req, err := NewRequest(requestString, nil)
 	if err != nil {
 		t.Errorf("error: %s", err)
 	}
@@ -283,3 +234,246 @@ func TestDoCtxTimeout(t *testing.T) {
 	_, err = req.Do(ctx, ",")
 	assert.ErrorIs(t, err, context.DeadlineExceeded)
 }
+
+func TestRequestDoWithoutWhere(t *testing.T) {
+	req, err := NewRequest("SELECT location FROM ./test/owid-covid-data.csv;", nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 22)
+	assert.Equal(t, IndexMap{}, result.ConditionInd)
+}
+
+func TestRequestDoLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		reqString string
+		expect    []string
+	}{
+		{
+			name:      "limitOffset",
+			reqString: "SELECT date FROM ./test/owid-covid-data.csv WHERE location = Russia LIMIT 3 OFFSET 2;",
+			expect:    []string{"2020-04-22", "2020-04-23", "2020-04-24"},
+		},
+		{
+			name:      "offsetOnly",
+			reqString: "SELECT date FROM ./test/owid-covid-data.csv WHERE location = Ukraine OFFSET 9;",
+			expect:    []string{"2020-04-29", "2020-04-30"},
+		},
+		{
+			name:      "ordered",
+			reqString: "SELECT date FROM ./test/owid-covid-data.csv ORDER BY new_cases DESC LIMIT 2 OFFSET 1;",
+			expect:    []string{"2020-04-28", "2020-04-26"},
+		},
+		{
+			name:      "zero",
+			reqString: "SELECT date FROM ./test/owid-covid-data.csv LIMIT 0;",
+			expect:    nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest(tc.reqString, nil)
+			if err != nil {
+				t.Errorf("error: %s", err)
+			}
+
+			result, err := req.Do(context.Background(), ",")
+			assert.Nil(t, err)
+
+			var dates []string
+			for _, row := range result.Data {
+				dates = append(dates, row["date"])
+			}
+			assert.Equal(t, tc.expect, dates)
+		})
+	}
+}
+
+func TestRequestDoLimitStopsScan(t *testing.T) {
+	// The scanner fails on the last line since it is too long,
+	// but it should never be read when the limit is reached.
+	csvFile := filepath.Join(t.TempDir(), "data.csv")
+	content := "location,date\nRussia,2020-04-20\nUkraine,2020-04-20\n" + strings.Repeat("x", bufio.MaxScanTokenSize+1)
+	if err := os.WriteFile(csvFile, []byte(content), 0600); err != nil {
+		t.Fatalf("cannot create csv file: %s", err)
+	}
+
+	req, err := NewRequest(fmt.Sprintf("SELECT location FROM '%s' LIMIT 1;", csvFile), nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{{"location": "Russia"}}, result.Data)
+
+	req.Limit = nil
+	_, err = req.Do(context.Background(), ",")
+	assert.ErrorIs(t, err, bufio.ErrTooLong)
+}
+
+func TestRequestDoColumnComparison(t *testing.T) {
+	req, err := NewRequest(`SELECT date FROM ./test/owid-covid-data.csv
+	WHERE new_deaths * 50 > new_cases AND location = Ukraine AND tests_units = new_cases;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+	assert.Equal(t, &Word{Text: "new_cases", Field: true}, req.Where.(*Logical).Left.(*Logical).Left.(*Criterion).Right)
+	assert.Equal(t, &Word{Text: "Ukraine"}, req.Where.(*Logical).Left.(*Logical).Right.(*Criterion).Right)
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Empty(t, result.Data)
+
+	req, err = NewRequest(`SELECT date FROM ./test/owid-covid-data.csv
+	WHERE new_deaths * 50 > new_cases AND location = Ukraine;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+	result, err = req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Len(t, result.Data, 9)
+	assert.Equal(t, IndexMap{"location": 2, "new_cases": 5, "new_deaths": 8}, result.ConditionInd)
+}
+
+func TestRequestDoIn(t *testing.T) {
+	req, err := NewRequest(`SELECT location, date FROM ./test/owid-covid-data.csv
+	WHERE location IN (ukraine, 'Belarus') AND date NOT IN (2020-04-20, 2020-04-21) AND new_deaths IN (11, 19);`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+
+	var dates []string
+	for _, row := range result.Data {
+		dates = append(dates, row["date"])
+	}
+	assert.Equal(t, []string{"2020-04-27", "2020-04-28", "2020-04-29", "2020-04-30"}, dates)
+}
+
+func TestRequestDoBetween(t *testing.T) {
+	req, err := NewRequest(`SELECT date FROM ./test/owid-covid-data.csv
+	WHERE date BETWEEN 2020-04-24 AND 2020-04-26 AND location = Ukraine AND new_cases NOT BETWEEN 478 AND 490;`, nil)
+	if err != nil {
+		t.Errorf("error: %s", err)
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, err)
+	assert.Equal(t, []RowData{{"date": "2020-04-24"}, {"date": "2020-04-26"}}, result.Data)
+}
+
+func TestRequestDoNotEqual(t *testing.T) {
+	const selectDates = "SELECT date FROM ./test/owid-covid-data.csv "
+	tests := []struct {
+		name      string
+		reqString string
+	}{
+		{name: "notEqual", reqString: selectDates + "WHERE location != Russia AND new_deaths <> 10;"},
+		{name: "oldForm", reqString: selectDates + "WHERE location NOT Russia AND new_deaths NOT 10;"},
+		{name: "unary", reqString: selectDates + "WHERE NOT (location = Russia OR new_deaths = 10);"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest(tc.reqString, nil)
+			if err != nil {
+				t.Errorf("error: %s", err)
+			}
+
+			result, err := req.Do(context.Background(), ",")
+			assert.Nil(t, err)
+			assert.Len(t, result.Data, 9)
+		})
+	}
+}
+
+func TestRequestDoOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		where   string
+		options *Options
+		expect  []string
+	}{
+		{name: "default", where: "verified IS NULL", options: nil, expect: []string{"c"}},
+		{name: "zero", where: "verified IS NULL", options: &Options{NullMarkers: []string{"0"}}, expect: []string{"b", "c"}},
+		{
+			name:    "one",
+			where:   "verified IS NULL",
+			options: &Options{NullMarkers: []string{"1"}},
+			expect:  []string{"a", "c", "d"},
+		},
+		{name: "false", where: "active = FALSE", options: nil, expect: []string{"d"}},
+		{name: "no", where: "active = FALSE", options: &Options{FalseValues: []string{"no"}}, expect: []string{"b", "d"}},
+	}
+
+	// Requests with different options can be done at the same time.
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			req, err := NewRequest("SELECT name FROM ./test/flags.csv WHERE "+tc.where, tc.options)
+			assert.NoError(t, err)
+			result, err := req.Do(context.Background(), ",")
+			assert.NoError(t, err)
+
+			names := make([]string, len(result.Data))
+			for ind, data := range result.Data {
+				names[ind] = data["name"]
+			}
+			assert.Equal(t, tc.expect, names)
+		})
+	}
+}
+
+func TestRequestDoWithoutOptions(t *testing.T) {
+	// The request built without NewRequest reads the file with the default options.
+	req := &Request{Select: selectColumns("name"), From: "./test/flags.csv"}
+	result, err := req.Do(context.Background(), ",")
+	assert.NoError(t, err)
+	assert.Equal(t, []RowData{{"name": "a"}, {"name": "b"}, {"name": "c"}, {"name": "d"}}, result.Data)
+}
+
+func TestRequestDoShortLines(t *testing.T) {
+	// Missing cells of the short lines are NULL.
+	tests := []struct {
+		name   string
+		str    string
+		expect []RowData
+	}{
+		{
+			name: "select",
+			str:  "SELECT name, value FROM ./test/short.csv",
+			expect: []RowData{
+				{"name": "a", "value": "1"}, {"name": "b", "value": ""}, {"name": "c", "value": ""}, {"name": "d", "value": "4"},
+			},
+		},
+		{
+			name:   "where",
+			str:    "SELECT name FROM ./test/short.csv WHERE code IS NULL",
+			expect: []RowData{{"name": "c"}},
+		},
+		{
+			name:   "aggregate",
+			str:    "SELECT COUNT(value) AS count FROM ./test/short.csv",
+			expect: []RowData{{"COUNT(value)": "2", "count": "2"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest(tc.str, nil)
+			assert.NoError(t, err)
+			result, err := req.Do(context.Background(), ",")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, result.Data)
+		})
+	}
+}
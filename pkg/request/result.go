@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -15,6 +16,40 @@ type IndexMap map[string]int
 // RowData is a custom type that defines lines of results from the csv file.
 type RowData map[string]string
 
+// Get returns value of the field in the row of results.
+func (d RowData) Get(field string) string {
+	return d[field]
+}
+
+// evalData returns the value of the expression in the row of results.
+// The value is taken by the name of the expression if the row already has it,
+// e.g. the selected item or the GROUP BY expression of the group.
+// The value keeps the declared type of the expression, e.g. of CAST.
+func evalData(expr Expression, data RowData, f *formats) Variable {
+	if _, ok := expr.(*Column); ok {
+		return expr.Eval(data)
+	}
+	if value, ok := data[expr.String()]; ok {
+		return fieldValue(value, declaredType(expr), f)
+	}
+	return expr.Eval(data)
+}
+
+// Row is a csv line together with the indexes of its fields.
+type Row struct {
+	Index IndexMap
+	Line  []string
+}
+
+// Get returns value of the field in the row.
+func (r Row) Get(field string) string {
+	ind, ok := r.Index[field]
+	if !ok || ind >= len(r.Line) {
+		return ""
+	}
+	return r.Line[ind]
+}
+
 // Results is an object containing result information.
 type Results struct {
 	Request      *Request
@@ -22,12 +57,19 @@ type Results struct {
 	ConditionInd IndexMap
 	MaxLength    IndexMap
 	Data         []RowData
+	aggregator   *aggregator
+	unique       hashSet
+	typedFields  []typedField
 	sync.Mutex
 	HasData bool
 }
 
 func (r *Results) fillConditionIndexes(headers []string) {
 	conditionInd := make(IndexMap)
+	if r.Request.Where == nil {
+		r.ConditionInd = conditionInd
+		return
+	}
 	fields := r.Request.Where.GetFields()
 
 	for ind, val := range headers {
@@ -46,72 +88,269 @@ func (r *Results) ParseCSVFile(ctx context.Context, csvSep string, resultDataCh
 	f, err := os.Open(r.Request.From)
 	if err != nil {
 		doneCh <- err
+		return
 	}
 	defer f.Close()
 
 	scanner := bufio.NewScanner(f)
 	scanner.Split(bufio.ScanLines)
 
-	firstRow := true
+	limit := r.Request.newStreamLimit()
+	var lineNumber int
+
 	for scanner.Scan() {
-		select {
-		case <-ctx.Done():
-			doneCh <- ctx.Err()
+		lineNumber++
+		if limit.reached() {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			doneCh <- err
 			return
-		default:
-			if firstRow {
-				firstRow = false
-				continue
-			}
+		}
+		if lineNumber == 1 {
+			continue
+		}
+
+		data, ok, err := r.processLine(scanner.Text(), csvSep, lineNumber)
+		if err != nil {
+			doneCh <- err
+			return
+		}
+		if !ok || limit.skip() {
+			continue
+		}
+		resultDataCh <- data
+		limit.sent++
+	}
+	doneCh <- scanner.Err()
+}
+
+// processLine returns the fields of the line required by the request.
+// The second result is false if the line does not satisfy the conditions
+// or it is the duplicate of DISTINCT request.
+func (r *Results) processLine(text, csvSep string, lineNumber int) (RowData, bool, error) {
+	line, err := r.readLine(text, csvSep, lineNumber)
+	if err != nil {
+		return nil, false, err
+	}
+	if !r.checkConditions(line) {
+		return nil, false, nil
+	}
+	data := r.createData(line)
+	if r.aggregator == nil && !r.isUnique(data) {
+		return nil, false, nil
+	}
+	return data, true, nil
+}
+
+// streamLimit applies offset and limit of the streamed request during the file scanning.
+type streamLimit struct {
+	limit   *int
+	offset  int
+	skipped int
+	sent    int
+}
 
-			line := strings.Split(scanner.Text(), csvSep)
-			if r.checkConditions(line) {
-				data := r.createData(line)
-				resultDataCh <- data
+// newStreamLimit returns the limit of the request, which has no effect
+// if the request is not streamed, see Request.streamed.
+func (r *Request) newStreamLimit() *streamLimit {
+	if !r.streamed() {
+		return &streamLimit{}
+	}
+	return &streamLimit{limit: r.Limit, offset: r.Offset}
+}
+
+// reached defines if the limit of the rows is already sent.
+func (l *streamLimit) reached() bool {
+	return l.limit != nil && l.sent >= *l.limit
+}
+
+// skip defines if the row should be skipped because of the offset.
+func (l *streamLimit) skip() bool {
+	if l.skipped < l.offset {
+		l.skipped++
+		return true
+	}
+	return false
+}
+
+// collect adds the row to the results or to its group if the request is aggregated.
+func (r *Results) collect(data RowData) {
+	if r.aggregator != nil {
+		r.aggregator.add(data)
+		return
+	}
+	r.Data = append(r.Data, data)
+}
+
+// finish groups, sorts and limits the collected rows unless it is done during the scanning.
+func (r *Results) finish() {
+	r.fillGroups()
+	if !r.Request.streamed() {
+		r.sortData()
+		r.limitData()
+	}
+}
+
+// fillGroups replaces the data with the rows of the groups.
+func (r *Results) fillGroups() {
+	if r.aggregator == nil {
+		return
+	}
+
+	r.Data = r.aggregator.rows()
+	for _, row := range r.Data {
+		for _, item := range r.Request.Select {
+			row[item.Name()] = evalData(item.Expr, row, r.Request.formats).String()
+		}
+	}
+	r.filterGroups()
+	r.filterDuplicates()
+	for _, data := range r.Data {
+		for field, value := range data {
+			if length, ok := r.MaxLength[field]; ok && length < len(value) {
+				r.MaxLength[field] = len(value)
 			}
 		}
 	}
-	doneCh <- nil
 }
 
-func (r *Results) checkConditions(line []string) bool {
-	for key := range r.ConditionInd {
-		lineData := line[r.ConditionInd[key]]
+// filterDuplicates removes the duplicate rows of the groups of DISTINCT request.
+func (r *Results) filterDuplicates() {
+	if !r.Request.Distinct {
+		return
+	}
 
-		if r.Request.Where == nil {
-			return true
+	data := r.Data[:0]
+	for _, row := range r.Data {
+		if r.isUnique(row) {
+			data = append(data, row)
 		}
+	}
+	r.Data = data
+}
+
+// isUnique defines if the selected values of the row were not met before.
+// It is always true if the request is not distinct.
+func (r *Results) isUnique(data RowData) bool {
+	if !r.Request.Distinct {
+		return true
+	}
+	if r.unique == nil {
+		r.unique = make(hashSet)
+	}
 
-		if !r.Request.Where.Condition(key, strings.ToLower(lineData)) {
-			return false
+	values := make([]string, len(r.Request.Select))
+	for ind, item := range r.Request.Select {
+		values[ind] = data[item.Name()]
+	}
+	return r.unique.add(hashValues(values...))
+}
+
+// filterGroups removes the groups which do not satisfy HAVING condition.
+func (r *Results) filterGroups() {
+	if r.Request.Having == nil {
+		return
+	}
+
+	data := r.Data[:0]
+	for _, row := range r.Data {
+		if r.Request.Having.Check(row) == True {
+			data = append(data, row)
 		}
 	}
-	return true
+	r.Data = data
 }
 
+// limitData applies offset and limit to the already collected data.
+func (r *Results) limitData() {
+	offset := r.Request.Offset
+	if offset > len(r.Data) {
+		offset = len(r.Data)
+	}
+	r.Data = r.Data[offset:]
+
+	if r.Request.Limit != nil && *r.Request.Limit < len(r.Data) {
+		r.Data = r.Data[:*r.Request.Limit]
+	}
+}
+
+func (r *Results) checkConditions(line []string) bool {
+	if r.Request.Where == nil {
+		return true
+	}
+	return r.Request.Where.Check(Row{Index: r.ConditionInd, Line: line}) == True
+}
+
+// createData returns the fields of the line required by the request.
+// Selected items are computed here unless the request is aggregated,
+// since then they are computed from the rows of the groups.
+// Missing cells of the short lines are NULL.
 func (r *Results) createData(line []string) RowData {
+	row := Row{Index: r.SelectInd, Line: line}
 	data := make(RowData)
-	for _, field := range r.Request.Select {
-		r.Lock()
-		ind := r.SelectInd[field]
-		data[field] = line[ind]
-		if r.MaxLength[field] < len(line[ind]) {
-			r.MaxLength[field] = len(line[ind])
+	for field := range r.SelectInd {
+		data[field] = row.Get(field)
+	}
+	if r.aggregator != nil {
+		return data
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	for _, item := range r.Request.Select {
+		name := item.Name()
+		data[name] = item.Expr.Eval(row).String()
+		if length := r.MaxLength[name]; length < len(data[name]) {
+			r.MaxLength[name] = len(data[name])
 		}
-		r.Unlock()
 	}
 	return data
 }
 
+// display returns the value as it is printed with the precision of the floats, see Options.
+// Negative precision means the floats are printed as they are.
+func display(value string, precision int) string {
+	if data := Data(value); precision >= 0 && data.defineType() == typeFloat {
+		return strconv.FormatFloat(data.toFloat(), 'f', precision, 64)
+	}
+	return value
+}
+
+// displayLengths returns the maximum lengths of the printed values of the fields.
+// They differ from MaxLength only if the floats are printed with the precision.
+func (r *Results) displayLengths(names []string, precision int) IndexMap {
+	if precision < 0 {
+		return r.MaxLength
+	}
+	lengths := make(IndexMap)
+	for _, key := range names {
+		lengths[key] = len(key)
+		for _, data := range r.Data {
+			if length := len(display(data[key], precision)); lengths[key] < length {
+				lengths[key] = length
+			}
+		}
+	}
+	return lengths
+}
+
 // Print prints results in table.
 func (r *Results) Print() {
 	if !r.HasData {
 		fmt.Println("nothing to print")
 		return
 	}
+	names := getSelectNames(r.Request.Select)
+	precision := -1
+	if digits := r.Request.getOptions().Precision; digits != nil {
+		precision = *digits
+	}
+	lengths := r.displayLengths(names, precision)
+
 	var line string = "|"
-	for _, key := range r.Request.Select {
-		length := r.MaxLength[key] + 2
+	for _, key := range names {
+		length := lengths[key] + 2
 		leftSide := (length - len(key)) / 2
 		rightSide := length - len(key) - leftSide
 		line = fmt.Sprintf("%s%s", line, strings.Repeat(" ", leftSide))
@@ -124,12 +363,13 @@ func (r *Results) Print() {
 
 	for _, data := range r.Data {
 		line = "|"
-		for _, key := range r.Request.Select {
-			length := r.MaxLength[key] + 2
-			leftSide := (length - len(data[key])) / 2
-			rightSide := length - len(data[key]) - leftSide
+		for _, key := range names {
+			value := display(data[key], precision)
+			length := lengths[key] + 2
+			leftSide := (length - len(value)) / 2
+			rightSide := length - len(value) - leftSide
 			line = fmt.Sprintf("%s%s", line, strings.Repeat(" ", leftSide))
-			line += data[key]
+			line += value
 			line = fmt.Sprintf("%s%s|", line, strings.Repeat(" ", rightSide))
 		}
 		fmt.Println(line)
@@ -24,7 +24,7 @@ func ExamplePrint() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	req, err := NewRequest(requestString)
+	req, err := NewRequest(requestString, nil)
 	if err != nil {
 		log.Printf("error: %s\n", err)
 	}
@@ -48,6 +48,50 @@ func ExamplePrint() {
 	// ==================================================
 }
 
+func ExampleResults_Print_options() {
+	// The options which do not set the precision print the floats as they are.
+	req, err := NewRequest(`SELECT date, new_cases, new_cases_smoothed FROM ./test/owid-covid-data.csv
+	WHERE location = Russia AND date = 2020-04-26`, &Options{NullMarkers: []string{"NA"}})
+	if err != nil {
+		log.Printf("error: %s\n", err)
+		return
+	}
+
+	result, err := req.Do(context.Background(), ",")
+	if err != nil {
+		log.Printf("error: %s\n", err)
+		return
+	}
+	result.Print()
+
+	// Output:
+	// ===============================================
+	// |    date    | new_cases | new_cases_smoothed |
+	// ===============================================
+	// | 2020-04-26 |  6361.0   |      5442.286      |
+	// ===============================================
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		result string
+	}{
+		{name: "float", value: "4113.286", result: "4113.29"},
+		{name: "wholeFloat", value: "4268.0", result: "4268.00"},
+		{name: "integer", value: "4268", result: "4268"},
+		{name: "date", value: "2020-04-20", result: "2020-04-20"},
+		{name: "empty", value: "", result: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, display(tc.value, 2), tc.result)
+		})
+	}
+}
+
 type TestVariable struct {
 	name      string
 	value     Data
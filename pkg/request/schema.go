@@ -0,0 +1,121 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// schemaExtension is appended to the path of the csv file to find its schema,
+// e.g. data.csv.schema is the schema of data.csv.
+const schemaExtension = ".schema"
+
+// Schema maps the fields of the csv file to their declared types.
+// Values of the declared fields are converted to the type when the file is read,
+// so the field has the same type in every row, see castValue.
+type Schema map[string]string
+
+// ParseSchema reads the types of the fields. Each line is written as "field: TYPE",
+// e.g. "new_cases: FLOAT". Types are INT, FLOAT, STRING, DATE, TIMESTAMP and BOOL.
+// Empty lines and lines starting with # are skipped.
+func ParseSchema(lines []string) (Schema, error) {
+	schema := make(Schema)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ind := strings.LastIndex(line, ":")
+		if ind <= 0 {
+			return nil, fmt.Errorf("cannot parse schema line: %s", line)
+		}
+		field, name := strings.TrimSpace(line[:ind]), strings.TrimSpace(line[ind+1:])
+		dataType, ok := typeNames[strings.ToUpper(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s of field: %s", name, field)
+		}
+		schema[field] = dataType
+	}
+	return schema, nil
+}
+
+// loadSchema returns the types of the fields of the csv file declared in the config schema
+// and in the schema file next to it. The result is nil if no types are declared.
+func loadSchema(csvFile string, headers []string, configSchema Schema) (Schema, error) {
+	var schema Schema
+	declare := func(field, dataType string) {
+		if schema == nil {
+			schema = make(Schema)
+		}
+		schema[field] = dataType
+	}
+
+	for field, dataType := range configSchema {
+		if sliceHasString(field, headers) {
+			declare(field, dataType)
+		}
+	}
+
+	content, err := os.ReadFile(csvFile + schemaExtension)
+	if errors.Is(err, os.ErrNotExist) {
+		return schema, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fileSchema, err := ParseSchema(strings.Split(string(content), "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, csvFile+schemaExtension)
+	}
+	for field, dataType := range fileSchema {
+		if !sliceHasString(field, headers) {
+			return nil, fmt.Errorf("cannot find schema field: %s in headers: %v", field, headers)
+		}
+		declare(field, dataType)
+	}
+	return schema, nil
+}
+
+// typedField is the declared field of the csv file together with its index in the line.
+type typedField struct {
+	name     string
+	index    int
+	dataType string
+}
+
+func (r *Results) fillTypedFields(headers []string) {
+	for ind, val := range headers {
+		if dataType, ok := r.Request.Schema[val]; ok {
+			r.typedFields = append(r.typedFields, typedField{name: val, index: ind, dataType: dataType})
+		}
+	}
+}
+
+// readLine splits the line of the file into cells, empties NULL markers
+// and converts the values of the declared fields.
+func (r *Results) readLine(text, csvSep string, lineNumber int) ([]string, error) {
+	line := strings.Split(text, csvSep)
+	clearNullMarkers(line, r.Request.getOptions().NullMarkers)
+	if err := r.convertLine(line, lineNumber); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// convertLine replaces the values of the declared fields in the line with the converted ones.
+// Values which cannot be converted become NULL or, in strict mode, the error is returned.
+func (r *Results) convertLine(line []string, lineNumber int) error {
+	for _, field := range r.typedFields {
+		if field.index >= len(line) {
+			continue
+		}
+		value, ok := castValue(r.Request.formats.parse(line[field.index]), field.dataType)
+		if !ok && r.Request.getOptions().StrictTypes {
+			return fmt.Errorf("cannot convert value %q of field: %s to %s on line %d",
+				line[field.index], field.name, field.dataType, lineNumber)
+		}
+		line[field.index] = value.String()
+	}
+	return nil
+}
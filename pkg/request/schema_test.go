@@ -0,0 +1,139 @@
+package request
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchema(t *testing.T) {
+	schema, err := ParseSchema([]string{"# comment", "", "new_cases: float", "date:DATE", " iso_code : TEXT "})
+	assert.NoError(t, err)
+	assert.Equal(t, Schema{"new_cases": typeFloat, "date": typeDate, "iso_code": typeString}, schema)
+
+	_, err = ParseSchema([]string{"new_cases FLOAT"})
+	assert.EqualError(t, err, "cannot parse schema line: new_cases FLOAT")
+	_, err = ParseSchema([]string{"new_cases: NUMBER"})
+	assert.EqualError(t, err, "unknown type NUMBER of field: new_cases")
+}
+
+func TestLoadSchema(t *testing.T) {
+	config, err := ParseSchema([]string{"id: STRING", "cases: FLOAT", "location: STRING"})
+	assert.NoError(t, err)
+
+	// The schema file overrides the config and the fields of other files are skipped.
+	schema, err := loadSchema("./test/typed.csv", []string{"id", "code", "cases", "reported"}, config)
+	assert.NoError(t, err)
+	assert.Equal(t, Schema{"id": typeString, "code": typeString, "cases": typeInteger, "reported": typeDate}, schema)
+
+	schema, err = loadSchema("./test/owid-covid-data.csv", []string{"iso_code", "location"}, config)
+	assert.NoError(t, err)
+	assert.Equal(t, Schema{"location": typeString}, schema)
+
+	_, err = loadSchema("./test/typed.csv", []string{"id", "code", "cases"}, config)
+	assert.EqualError(t, err, "cannot find schema field: reported in headers: [id code cases]")
+}
+
+func TestRequestDoSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		expect []string
+	}{
+		{
+			name:   "stringIsNotNumber",
+			str:    "SELECT id FROM ./test/typed.csv WHERE code = '007'",
+			expect: []string{"1"},
+		},
+		{
+			name:   "stringWord",
+			str:    "SELECT id FROM ./test/typed.csv WHERE code IN (7, 70)",
+			expect: []string{"2"},
+		},
+		{
+			name:   "stringOrder",
+			str:    "SELECT id FROM ./test/typed.csv ORDER BY code",
+			expect: []string{"1", "3", "2"},
+		},
+		{
+			name:   "invalidIsNull",
+			str:    "SELECT id FROM ./test/typed.csv WHERE cases IS NULL",
+			expect: []string{"2"},
+		},
+		{
+			name:   "integerSum",
+			str:    "SELECT SUM(cases) FROM ./test/typed.csv",
+			expect: []string{"22"},
+		},
+		{
+			name:   "stringGreater",
+			str:    "SELECT id FROM ./test/typed.csv WHERE code > '0070'",
+			expect: []string{"2"},
+		},
+		{
+			name:   "stringBetween",
+			str:    "SELECT id FROM ./test/codes.csv WHERE CAST(code AS STRING) BETWEEN '1' AND '2' ORDER BY id",
+			expect: []string{"1", "3"},
+		},
+		{
+			name:   "stringMax",
+			str:    "SELECT MAX(code) FROM ./test/typed.csv",
+			expect: []string{"7"},
+		},
+		{
+			name:   "stringSum",
+			str:    "SELECT SUM(code) FROM ./test/typed.csv",
+			expect: []string{""},
+		},
+		{
+			name:   "aliasOrder",
+			str:    "SELECT id, code AS c FROM ./test/typed.csv ORDER BY c",
+			expect: []string{"1", "3", "2"},
+		},
+		{
+			name:   "castOrder",
+			str:    "SELECT id FROM ./test/codes.csv ORDER BY CAST(code AS STRING)",
+			expect: []string{"3", "1", "2"},
+		},
+		{
+			name:   "selectedCastOrder",
+			str:    "SELECT id, CAST(code AS STRING) FROM ./test/codes.csv ORDER BY CAST(code AS STRING)",
+			expect: []string{"3", "1", "2"},
+		},
+		{
+			name:   "castAliasOrder",
+			str:    "SELECT id, CAST(code AS STRING) AS c FROM ./test/codes.csv ORDER BY c DESC",
+			expect: []string{"2", "1", "3"},
+		},
+		{
+			name:   "castToNumber",
+			str:    "SELECT id FROM ./test/typed.csv WHERE CAST(code AS INT) = 7",
+			expect: []string{"1", "2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := NewRequest(tc.str, nil)
+			assert.NoError(t, err)
+			result, err := req.Do(context.Background(), ",")
+			assert.NoError(t, err)
+
+			name := getSelectNames(req.Select)[0]
+			values := make([]string, len(result.Data))
+			for ind, data := range result.Data {
+				values[ind] = data[name]
+			}
+			assert.Equal(t, tc.expect, values)
+		})
+	}
+}
+
+func TestRequestDoStrictTypes(t *testing.T) {
+	req, err := NewRequest("SELECT id, cases FROM ./test/typed.csv", &Options{StrictTypes: true})
+	assert.NoError(t, err)
+	result, err := req.Do(context.Background(), ",")
+	assert.Nil(t, result)
+	assert.EqualError(t, err, `cannot convert value "N/A" of field: cases to INT on line 3`)
+}